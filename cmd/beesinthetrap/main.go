@@ -1,24 +1,152 @@
 package main
 
 import (
+	"errors"
+	"flag"
+	"fmt"
+	"io/fs"
 	"log"
+	"os"
+	"runtime"
 
 	"github.com/joho/godotenv"
 	"github.com/lewwolfe/beesinthetrap/internal/cli"
 	"github.com/lewwolfe/beesinthetrap/internal/config"
 	"github.com/lewwolfe/beesinthetrap/internal/game"
+	"github.com/lewwolfe/beesinthetrap/internal/sim"
+	"github.com/lewwolfe/beesinthetrap/pkg/hive"
 )
 
 func main() {
-	// load in .env file for config options
+	resume := flag.String("resume", "", "resume a saved game from `file`")
+	record := flag.String("record", "", "write a replay of the game to `file` when it ends")
+	name := flag.String("name", "", "player `name`, skips the name prompt")
+	auto := flag.Bool("auto", false, "run the game automatically, skips the auto mode prompt")
+	configFlags := config.RegisterFlags(flag.CommandLine)
+	flag.Parse()
+
+	switch flag.Arg(0) {
+	case "replay":
+		runReplay(flag.Arg(1))
+		return
+	case "simulate":
+		runSimulate(flag.Args()[1:])
+		return
+	}
+
+	loadEnv()
+
+	var opts []cli.Option
+	var g *hive.Game
+	var err error
+	if *resume != "" {
+		g, err = hive.Load(*resume)
+		if err != nil {
+			log.Fatalf("Error resuming game: %v", err)
+		}
+	} else {
+		cfg := loadConfig(configFlags)
+		g, err = hive.New(hive.WithConfig(cfg))
+		if err != nil {
+			log.Fatalf("Invalid config:\n%v", err)
+		}
+
+		// Only prompt for a class or loadout the config doesn't choose
+		if cfg.Class != "" {
+			opts = append(opts, cli.WithClass(cfg.Class))
+		}
+		if cfg.Loadout != "" {
+			opts = append(opts, cli.WithLoadout(cfg.Loadout))
+		}
+	}
+
+	// Only skip the prompts for options that were given
+	flag.Visit(func(f *flag.Flag) {
+		switch f.Name {
+		case "name":
+			opts = append(opts, cli.WithPlayerName(*name))
+		case "auto":
+			opts = append(opts, cli.WithAutoMode(*auto))
+		}
+	})
+
+	gameCLI := cli.NewGameCLI(g, opts...)
+	gameCLI.Start()
+
+	if *record != "" {
+		// Record the game that was played last, which is a loaded one if the
+		// player loaded a save
+		if err := gameCLI.Game().SaveReplay(*record); err != nil {
+			log.Fatalf("Error saving replay: %v", err)
+		}
+	}
+}
+
+func loadEnv() {
+	// load in .env file for config options, if there is one
 	err := godotenv.Load()
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		log.Fatalf("Error loading .env file: %v", err)
+	}
+}
+
+func loadConfig(configFlags *config.Flags) *config.Config {
+	cfg, err := configFlags.Load()
+	if err != nil {
+		log.Fatalf("Invalid config:\n%v", err)
+	}
+	return cfg
+}
+
+func runReplay(path string) {
+	if path == "" {
+		log.Fatal("Usage: beesinthetrap replay <file>")
+	}
+
+	replay, err := game.LoadReplay(path)
 	if err != nil {
-		log.Fatal("Error loading .env file")
+		log.Fatalf("Error loading replay: %v", err)
 	}
 
-	cfg := config.LoadConfig()
-	game := game.NewGame(cfg)
+	if err := game.RunReplay(replay); err != nil {
+		log.Fatalf("Replay failed: %v", err)
+	}
+	if !replay.Finished {
+		fmt.Printf("Replay OK: %d events of an unfinished game matched\n", len(replay.Events))
+		return
+	}
+	fmt.Printf("Replay OK: %d events matched\n", len(replay.Events))
+}
 
-	gameCLI := cli.NewGameCLI(game)
-	gameCLI.Start()
+func runSimulate(args []string) {
+	simFlags := flag.NewFlagSet("simulate", flag.ExitOnError)
+	games := simFlags.Int("games", 1000, "number of games to simulate")
+	workers := simFlags.Int("workers", runtime.NumCPU(), "number of games to run in parallel")
+	format := simFlags.String("format", "table", "output format: table, json or csv")
+	configFlags := config.RegisterFlags(simFlags)
+	simFlags.Parse(args)
+
+	if *games < 0 {
+		log.Fatalf("Invalid -games: can't be negative, got %d", *games)
+	}
+	if err := sim.CheckFormat(*format); err != nil {
+		log.Fatalf("Invalid -format: %v", err)
+	}
+
+	loadEnv()
+	cfg := loadConfig(configFlags)
+
+	// The seed is for the first game, each following game adds one
+	seed := cfg.RandomSeed
+	if seed == 0 {
+		seed = 1
+	}
+
+	report, err := sim.Run(cfg, sim.Options{Games: *games, Seed: seed, Workers: *workers})
+	if err != nil {
+		log.Fatalf("Error simulating games: %v", err)
+	}
+	if err := report.Write(os.Stdout, *format); err != nil {
+		log.Fatalf("Error writing report: %v", err)
+	}
 }
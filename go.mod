@@ -2,4 +2,8 @@ module github.com/lewwolfe/beesinthetrap
 
 go 1.24.1
 
-require github.com/joho/godotenv v1.5.1
+require (
+	github.com/BurntSushi/toml v1.6.0
+	github.com/joho/godotenv v1.5.1
+	gopkg.in/yaml.v3 v3.0.1
+)
@@ -6,33 +6,85 @@ import (
 	"fmt"
 	"os"
 	"os/signal"
+	"strconv"
 	"strings"
 	"sync"
 	"syscall"
 	"time"
 
-	"github.com/lewwolfe/beesinthetrap/internal/game"
+	"github.com/lewwolfe/beesinthetrap/pkg/hive"
 )
 
 type GameCLI struct {
-	gameEngine *game.GameEngine
-	playerName string
-	autoMode   bool
-	scanner    *bufio.Scanner
-	gameLogs   []string
+	gameEngine  *hive.Game
+	playerName  string
+	autoMode    bool
+	autoModeSet bool
+	class       string
+	loadout     string
+	scanner     *bufio.Scanner
+	gameLogs    []string
 }
 
-func NewGameCLI(gameEngine *game.GameEngine) *GameCLI {
-	return &GameCLI{
+// Option presets a choice the CLI would otherwise prompt the player for
+type Option func(c *GameCLI)
+
+func WithPlayerName(name string) Option {
+	return func(c *GameCLI) {
+		c.playerName = strings.TrimSpace(name)
+	}
+}
+
+func WithAutoMode(auto bool) Option {
+	return func(c *GameCLI) {
+		c.autoMode = auto
+		c.autoModeSet = true
+	}
+}
+
+// WithClass plays as the class, unless the game was resumed
+func WithClass(name string) Option {
+	return func(c *GameCLI) {
+		c.class = name
+	}
+}
+
+// WithLoadout plays with the loadout, unless the game was resumed
+func WithLoadout(name string) Option {
+	return func(c *GameCLI) {
+		c.loadout = name
+	}
+}
+
+func NewGameCLI(gameEngine *hive.Game, opts ...Option) *GameCLI {
+	c := &GameCLI{
 		gameEngine: gameEngine,
 		scanner:    bufio.NewScanner(os.Stdin),
 	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
 }
 
 func (c *GameCLI) Start() {
 	c.displayWelcomeBanner()
-	c.promptPlayerName()
-	c.promptAutoMode()
+	if c.playerName == "" {
+		c.promptPlayerName()
+	}
+	// A resumed game carries on with the class and loadout it was saved with
+	if !c.gameEngine.Resumed() {
+		cfg := c.gameEngine.View().Config
+		if len(cfg.Classes) > 1 && (c.class == "" || c.gameEngine.SetClass(c.class) != nil) {
+			c.promptClass()
+		}
+		if len(cfg.Loadouts) > 1 && (c.loadout == "" || c.gameEngine.SetLoadout(c.loadout) != nil) {
+			c.promptLoadout()
+		}
+	}
+	if !c.autoModeSet {
+		c.promptAutoMode()
+	}
 	c.runGame()
 }
 
@@ -47,6 +99,63 @@ func (c *GameCLI) promptPlayerName() {
 	fmt.Printf("Welcome, %s!\n\n", c.playerName)
 }
 
+// promptClass lets the player pick their class, taking the first class if
+// they just press enter
+func (c *GameCLI) promptClass() {
+	classes := c.gameEngine.View().Config.Classes
+	fmt.Println("Choose your class:")
+	names := make([]string, 0, len(classes))
+	for i, class := range classes {
+		fmt.Printf("  %d) %s: %s\n", i+1, class.Name, class.Description)
+		names = append(names, class.Name)
+	}
+	c.promptChoice("Class", names, c.gameEngine.SetClass)
+
+	class := c.gameEngine.View().Player.Class
+	if class.Ability.Name != "" {
+		fmt.Printf("You are a %s, type 'special' to use %s.\n\n", class.Name, class.Ability.Name)
+	} else {
+		fmt.Printf("You are a %s.\n\n", class.Name)
+	}
+}
+
+// promptLoadout lets the player pick their weapon and armor, taking the first
+// loadout if they just press enter
+func (c *GameCLI) promptLoadout() {
+	loadouts := c.gameEngine.View().Config.Loadouts
+	fmt.Println("Choose your gear:")
+	names := make([]string, 0, len(loadouts))
+	for i, l := range loadouts {
+		fmt.Printf("  %d) %s: %s and %s\n", i+1, l.Name, l.Weapon.Name, l.Armor.Name)
+		names = append(names, l.Name)
+	}
+	c.promptChoice("Loadout", names, c.gameEngine.SetLoadout)
+
+	loadout := c.gameEngine.View().Player.Loadout
+	fmt.Printf("You grab the %s and pull on the %s.\n\n", loadout.Weapon.Name, loadout.Armor.Name)
+}
+
+// promptChoice asks until the player picks one of the numbered names, or a
+// name itself, and choose accepts it. Just pressing enter picks the first.
+func (c *GameCLI) promptChoice(label string, names []string, choose func(name string) error) {
+	for {
+		fmt.Printf("%s (enter for the first): ", label)
+		if !c.scanner.Scan() {
+			break
+		}
+
+		input := strings.TrimSpace(c.scanner.Text())
+		name := input
+		if n, err := strconv.Atoi(input); err == nil && n >= 1 && n <= len(names) {
+			name = names[n-1]
+		}
+		if err := choose(name); err == nil {
+			break
+		}
+		fmt.Printf("Please enter a number from 1 to %d or a %s name.\n", len(names), strings.ToLower(label))
+	}
+}
+
 func (c *GameCLI) promptAutoMode() {
 	for {
 		fmt.Print("Do you want the game to run automatically? (y/n): ")
@@ -62,6 +171,10 @@ func (c *GameCLI) promptAutoMode() {
 		} else if input == "n" || input == "no" {
 			c.autoMode = false
 			fmt.Println("Manual mode activated. You'll need to type 'hit' to attack.")
+			fmt.Println("Aim with 'hit queen' or 'hit 3' (the number shown in the hive listing), but aimed shots miss more often.")
+			fmt.Println("You can also 'defend' or 'dodge' the next sting, 'heal' a few times per game, or 'flee' the hive.")
+			fmt.Println("Your class has a 'special' ability, which needs a few turns to recharge after each use.")
+			fmt.Println("Type 'save <file>' or 'load <file>' on your turn to save or load the hive.")
 			break
 		}
 		fmt.Println("Please enter 'y' or 'n'.")
@@ -69,51 +182,73 @@ func (c *GameCLI) promptAutoMode() {
 	fmt.Println()
 }
 
+// runGame plays the game until it ends or the player interrupts it
 func (c *GameCLI) runGame() {
-	ctx, cancel := context.WithCancel(context.Background())
-	defer cancel()
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	c.play(ctx)
+}
+
+// Game returns the game being played, which is a new one once the player
+// has loaded a save
+func (c *GameCLI) Game() *hive.Game {
+	return c.gameEngine
+}
 
-	// Set up signal handling
-	c.setupSignalHandling(ctx, cancel)
+// play runs the game until it is over or ctx is cancelled, switching to the
+// loaded game whenever the player loads a save
+func (c *GameCLI) play(ctx context.Context) {
+	lines := c.readInput(ctx)
+	for {
+		gameState, over, loaded := c.runEngine(ctx, lines)
+		if loaded == nil {
+			if over {
+				time.Sleep(200 * time.Millisecond)
+				c.displayGameOver(ctx, gameState, lines)
+			}
+			return
+		}
+		c.gameEngine = loaded
+	}
+}
 
+// runEngine runs the game engine and the goroutines feeding it until the
+// game is over, the player loads a save or ctx is cancelled. Every goroutine
+// it waits on returns once ctx is done, so it always returns promptly after
+// an interrupt.
+func (c *GameCLI) runEngine(ctx context.Context, lines <-chan string) (gameState hive.State, over bool, loaded *hive.Game) {
 	// Clear the screen and display game interface
 	c.clearScreen()
-	c.displayGameInterface()
+	c.displayGameInterface(c.gameEngine.View())
 
 	// Start all goroutines
+	gameCtx, cancel := context.WithCancel(ctx)
 	var wg sync.WaitGroup
-	c.startGameRoutines(ctx, &wg)
+	notes := make(chan hive.Event)
+	loads := make(chan *hive.Game, 1)
+	c.startGameRoutines(gameCtx, &wg, lines, notes, loads)
 
 	// Wait for game state event
 	select {
 	case <-ctx.Done():
-		return
-	case gameState := <-c.gameEngine.GameStateChan:
-		time.Sleep(200 * time.Millisecond)
-		c.displayGameOver(gameState)
-		ctx.Done()
+		fmt.Println("\nGame interrupted! Shutting down...")
+	case gameState = <-c.gameEngine.Result():
+		over = true
+	case loaded = <-loads:
 	}
 
+	// Stop the game routines before the game over screen takes the input
+	cancel()
 	wg.Wait()
+	return gameState, over, loaded
 }
 
-func (c *GameCLI) setupSignalHandling(ctx context.Context, cancel context.CancelFunc) {
-	defer ctx.Done()
-	sigChan := make(chan os.Signal, 1)
-	signal.Notify(sigChan, os.Interrupt, syscall.SIGTERM)
-	go func() {
-		<-sigChan
-		fmt.Println("\nGame interrupted! Shutting down...")
-		cancel()
-	}()
-}
-
-func (c *GameCLI) startGameRoutines(ctx context.Context, wg *sync.WaitGroup) {
+func (c *GameCLI) startGameRoutines(ctx context.Context, wg *sync.WaitGroup, lines <-chan string, notes chan hive.Event, loads chan<- *hive.Game) {
 	// Start game output reader
 	wg.Add(1)
 	go func() {
 		defer wg.Done()
-		c.monitorGameOutput(ctx)
+		c.monitorGameOutput(ctx, notes)
 	}()
 
 	// Start input handler if not in auto mode
@@ -121,7 +256,7 @@ func (c *GameCLI) startGameRoutines(ctx context.Context, wg *sync.WaitGroup) {
 		wg.Add(1)
 		go func() {
 			defer wg.Done()
-			c.handleUserInput(ctx)
+			c.handleUserInput(ctx, lines, notes, loads)
 		}()
 	}
 
@@ -129,33 +264,106 @@ func (c *GameCLI) startGameRoutines(ctx context.Context, wg *sync.WaitGroup) {
 	wg.Add(1)
 	go func() {
 		defer wg.Done()
-		c.gameEngine.Start(c.autoMode, ctx)
+		// Run only fails when ctx is cancelled, which play already handles
+		_ = c.gameEngine.Run(ctx, c.autoMode)
 	}()
 }
 
-func (c *GameCLI) monitorGameOutput(ctx context.Context) {
+// monitorGameOutput logs the game's events, along with notes from the CLI
+// itself such as a game being saved
+func (c *GameCLI) monitorGameOutput(ctx context.Context, notes <-chan hive.Event) {
 	for {
 		select {
 		case <-ctx.Done():
 			return
-		case msg := <-c.gameEngine.OutputChan:
-			c.gameLogs = append(c.gameLogs, msg)
+		case event := <-c.gameEngine.Events():
+			c.gameLogs = append(c.gameLogs, formatEvent(event))
+			c.displayMessage()
+		case note := <-notes:
+			c.gameLogs = append(c.gameLogs, formatEvent(note))
 			c.displayMessage()
 		}
 	}
 }
 
-func (c *GameCLI) handleUserInput(ctx context.Context) {
-	for c.scanner.Scan() {
-		input := strings.TrimSpace(c.scanner.Text())
+// readInput scans the player's input in the background. A read from the
+// terminal can't be cancelled, so this is the one goroutine play doesn't
+// wait for: it stops at the end of the input, or at the next line once ctx
+// is done.
+func (c *GameCLI) readInput(ctx context.Context) <-chan string {
+	lines := make(chan string)
+	go func() {
+		defer close(lines)
+		for c.scanner.Scan() {
+			select {
+			case lines <- strings.TrimSpace(c.scanner.Text()):
+			case <-ctx.Done():
+				return
+			}
+		}
+
+		if err := c.scanner.Err(); err != nil {
+			fmt.Printf("Error reading input: %v\n", err)
+		}
+	}()
+	return lines
+}
+
+// handleUserInput passes the player's actions on to the engine. Saving and
+// loading are handled here rather than by the engine, which only takes game
+// actions: a loaded game is sent on loads for play to carry on with.
+func (c *GameCLI) handleUserInput(ctx context.Context, lines <-chan string, notes chan<- hive.Event, loads chan<- *hive.Game) {
+	for {
 		select {
 		case <-ctx.Done():
 			return
-		case c.gameEngine.InputChan <- input:
+		case input, ok := <-lines:
+			if !ok {
+				return
+			}
+
+			var note hive.Event
+			var loaded *hive.Game
+			if path, ok := strings.CutPrefix(input, "save "); ok {
+				note = c.save(strings.TrimSpace(path))
+			} else if path, ok := strings.CutPrefix(input, "load "); ok {
+				loaded, note = c.load(strings.TrimSpace(path))
+			} else {
+				select {
+				case <-ctx.Done():
+					return
+				case c.gameEngine.Input() <- input:
+				}
+				continue
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case notes <- note:
+			}
+			if loaded != nil {
+				loads <- loaded
+				return
+			}
 		}
 	}
+}
+
+// save writes the game to path, returning the note to log about it
+func (c *GameCLI) save(path string) hive.Event {
+	if err := c.gameEngine.Save(path); err != nil {
+		return hive.Event{Type: hive.CommandFailed, Input: "save " + path, Err: err.Error()}
+	}
+	return hive.Event{Type: hive.GameSaved, Path: path}
+}
 
-	if err := c.scanner.Err(); err != nil {
-		fmt.Printf("Error reading input: %v\n", err)
+// load reads a saved game from path, returning it and the note to log about
+// it. The game is nil if it couldn't be loaded.
+func (c *GameCLI) load(path string) (*hive.Game, hive.Event) {
+	loaded, err := hive.Load(path)
+	if err != nil {
+		return nil, hive.Event{Type: hive.CommandFailed, Input: "load " + path, Err: err.Error()}
 	}
+	return loaded, hive.Event{Type: hive.GameLoaded, Path: path}
 }
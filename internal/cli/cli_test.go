@@ -3,10 +3,18 @@ package cli
 import (
 	"bufio"
 	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"runtime"
+	"strings"
 	"testing"
+	"time"
 
-	"github.com/lewwolfe/beesinthetrap/internal/config"
-	"github.com/lewwolfe/beesinthetrap/internal/game"
+	"github.com/lewwolfe/beesinthetrap/pkg/hive"
 )
 
 func TestPromptPlayerName(t *testing.T) {
@@ -48,7 +56,7 @@ func TestPromptAutoMode(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.input, func(t *testing.T) {
-			cli := NewGameCLI(game.NewGame(config.LoadConfig()))
+			cli := NewGameCLI(newGame(t, hive.DefaultConfig()))
 			cli.scanner = bufio.NewScanner(bytes.NewReader([]byte(tt.input + "\n")))
 
 			cli.promptAutoMode()
@@ -60,3 +68,329 @@ func TestPromptAutoMode(t *testing.T) {
 		})
 	}
 }
+
+func TestPromptLoadout(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"2", "rake"},
+		{"Swatter", "swatter"},
+		{"", "newspaper"},
+		{"9\nrake", "rake"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			cli := NewGameCLI(newGame(t, hive.DefaultConfig()))
+			cli.scanner = bufio.NewScanner(bytes.NewReader([]byte(tt.input + "\n")))
+
+			cli.promptLoadout()
+
+			if got := cli.gameEngine.View().Player.Loadout.Name; got != tt.expected {
+				t.Errorf("Expected loadout to be '%s', got '%s'", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestPromptClass(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+		hp       int
+	}{
+		{"", "Beekeeper", 120},
+		{"2", "Exterminator", 90},
+		{"scout", "Scout", 100},
+		{"wizard\n3", "Scout", 100},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			cli := NewGameCLI(newGame(t, hive.DefaultConfig()))
+			cli.scanner = bufio.NewScanner(bytes.NewReader([]byte(tt.input + "\n")))
+
+			cli.promptClass()
+
+			player := cli.gameEngine.View().Player
+			if got := player.Class.Name; got != tt.expected {
+				t.Errorf("Expected class to be '%s', got '%s'", tt.expected, got)
+			}
+			if player.HP != tt.hp || player.MaxHP != tt.hp {
+				t.Errorf("Expected %s to start on %d HP, got %d/%d", tt.expected, tt.hp, player.HP, player.MaxHP)
+			}
+		})
+	}
+}
+
+func TestFormatEvent(t *testing.T) {
+	tests := []struct {
+		event    hive.Event
+		expected string
+	}{
+		{hive.Event{Type: hive.PlayerPrompt}, "Your move: 'hit' (or 'hit <type>' / 'hit <number>' to aim), 'defend', 'dodge', 'heal', 'use <item>', 'special' or 'flee'..."},
+		{hive.Event{Type: hive.InvalidCommand, Input: "kick"}, "Invalid command! 'kick'"},
+		{hive.Event{Type: hive.InvalidCommand, Input: "hit 40", Err: "there is no bee #40"}, "Invalid command! 'hit 40' (there is no bee #40)"},
+		{hive.Event{Type: hive.BeeHit, BeeType: hive.WorkerBee, BeeID: 4, Damage: 25, HP: 50}, "🧑 Direct Hit! Worker #4 took 25 damage, 50 HP left."},
+		{hive.Event{Type: hive.BeeKilled, BeeType: hive.DroneBee, BeeID: 12}, "💀 You killed Drone #12!"},
+		{hive.Event{Type: hive.BeeStung, BeeType: hive.QueenBee, BeeID: 31, Damage: 10, HP: 90}, "🐝 Ouch! Queen #31 stung you for 10 damage!"},
+		{hive.Event{Type: hive.BeeStung, BeeType: hive.WorkerBee, BeeID: 2, Damage: 3, Blocked: 2}, "🛡️ Worker #2 stung you for 3 damage, you blocked 2!"},
+		{hive.Event{Type: hive.PlayerHealed, Healed: 25, HP: 80}, "💚 You healed 25 HP, you now have 80 HP."},
+		{hive.Event{Type: hive.ItemUsed, Item: "smoke", Turns: 2}, "🧰 You used smoke, it lasts 2 turns."},
+		{hive.Event{Type: hive.ItemUsed, Item: "spray"}, "🧰 You used spray!"},
+		{hive.Event{Type: hive.AbilityUsed, Ability: "calm", Turns: 2}, "✨ You use your calm ability, it lasts 2 turns."},
+		{hive.Event{Type: hive.AbilityReady, Ability: "calm"}, "🔋 Your calm ability is ready again."},
+		{hive.Event{Type: hive.BeesPacified, Turns: 1}, "😴 The bees are too calm to attack."},
+		{hive.Event{Type: hive.BeeHealed, BeeType: hive.WorkerBee, BeeID: 3, OtherType: hive.QueenBee, OtherID: 1, Healed: 10, HP: 90}, "🩹 Worker #3 healed Queen #1 for 10 HP, 90 HP left."},
+		{hive.Event{Type: hive.BeeGuarded, BeeType: "Guard", BeeID: 5, OtherType: hive.QueenBee, OtherID: 1}, "🛡️ Guard #5 threw itself in front of Queen #1!"},
+		{hive.Event{Type: hive.SwarmAttacked, Count: 3, Damage: 11, HP: 89}, "🐝🐝 The swarm stung you 3 times for 11 damage, 89 HP left."},
+		{hive.Event{Type: hive.StatusApplied, Status: hive.Poisoned, Turns: 3, Stacks: 2}, "🤢 The sting leaves you poisoned x2 (3 turns)!"},
+		{hive.Event{Type: hive.StatusApplied, Status: hive.Stunned, Turns: 1, Stacks: 1}, "💫 The sting leaves you stunned (1 turn)!"},
+		{hive.Event{Type: hive.StatusApplied, Status: hive.Enraged, BeeType: hive.DroneBee, Turns: 3, Count: 24}, "😡 24 Drone bees are enraged by the loss of one of their own!"},
+		{hive.Event{Type: hive.StatusDamage, Status: hive.Poisoned, Damage: 2, HP: 70}, "🤢 Poison deals 2 damage, 70 HP left."},
+		{hive.Event{Type: hive.StatusExpired, Status: hive.Stunned}, "✨ You are no longer stunned."},
+		{hive.Event{Type: hive.BeeSpawned, BeeType: hive.DroneBee, BeeID: 32, HP: 60}, "🥚 A new Drone #32 hatched and joined the hive!"},
+		{hive.Event{Type: hive.GameOver, State: hive.PlayerLose}, "💀 You have been defeated by the hive!"},
+		{hive.Event{Type: hive.GameOver, State: hive.PlayerFled}, "🏃 You escaped the hive, live to fight another day!"},
+		{hive.Event{Type: hive.GameOver, State: hive.PlayerWin}, "🏆 Congratulations! You've destroyed the entire hive!"},
+		{hive.Event{Type: hive.GameSaved, Path: "hive.json"}, "💾 Game saved to hive.json"},
+		{hive.Event{Type: hive.CommandFailed, Input: "load hive.json", Err: "no such file"}, "⚠️ Could not load hive.json: no such file"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.event.Type.String(), func(t *testing.T) {
+			if msg := formatEvent(tt.event); msg != tt.expected {
+				t.Errorf("Expected message '%s', got '%s'", tt.expected, msg)
+			}
+		})
+	}
+}
+
+func TestOptionsSkipPrompts(t *testing.T) {
+	cli := NewGameCLI(nil, WithPlayerName("  Scripted  "), WithAutoMode(false))
+
+	if cli.playerName != "Scripted" {
+		t.Errorf("Expected playerName to be 'Scripted', got '%s'", cli.playerName)
+	}
+
+	if !cli.autoModeSet || cli.autoMode {
+		t.Errorf("Expected auto mode to be preset to false, got set=%v auto=%v", cli.autoModeSet, cli.autoMode)
+	}
+}
+
+func TestListBees(t *testing.T) {
+	bees, ids := []string{}, []int{}
+	for i := 1; i <= maxListedBees+3; i++ {
+		bees = append(bees, fmt.Sprintf("#%d 60", i))
+		ids = append(ids, i)
+	}
+
+	if got := listBees(bees[:2], ids[:2]); got != "#1 60, #2 60" {
+		t.Errorf("Expected short list in full, got '%s'", got)
+	}
+	want := fmt.Sprintf(", ... 3 more: #%d-%d", maxListedBees+1, maxListedBees+3)
+	if got := listBees(bees, ids); !strings.HasSuffix(got, want) || strings.Contains(got, fmt.Sprintf("#%d ", maxListedBees+1)) {
+		t.Errorf("Expected long list to end with the IDs of the rest after %d bees, got '%s'", maxListedBees, got)
+	}
+}
+
+func TestIDRanges(t *testing.T) {
+	tests := []struct {
+		ids      []int
+		expected string
+	}{
+		{[]int{13}, "#13"},
+		{[]int{13, 14, 15}, "#13-15"},
+		{[]int{13, 15, 16, 20}, "#13, #15-16, #20"},
+	}
+
+	for _, tt := range tests {
+		if got := idRanges(tt.ids); got != tt.expected {
+			t.Errorf("idRanges(%v) = '%s', want '%s'", tt.ids, got, tt.expected)
+		}
+	}
+}
+
+func TestCollapseRule(t *testing.T) {
+	tests := []struct {
+		rule     string
+		queens   int
+		expected string
+	}{
+		{hive.CollapseFirst, 2, "👑 Kill the Queen and the hive collapses!"},
+		{hive.CollapseLast, 1, "👑 Kill the Queen and the hive collapses!"},
+		{hive.CollapseLast, 3, "👑 Kill all 3 of the Queen bees and the hive collapses!"},
+		{hive.CollapseNever, 1, "🐝 The hive won't collapse when a Queen dies, you'll have to kill every bee!"},
+		{hive.CollapseFirst, 0, "🐝 There's no queen to kill, so you'll have to kill every bee!"},
+	}
+
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("%s %d", tt.rule, tt.queens), func(t *testing.T) {
+			cfg := hive.DefaultConfig()
+			cfg.CollapseRule = tt.rule
+			cfg.Species[0].Amount = tt.queens
+
+			if msg := collapseRule(cfg); msg != tt.expected {
+				t.Errorf("Expected message '%s', got '%s'", tt.expected, msg)
+			}
+		})
+	}
+}
+
+// waitForGoroutines waits for the number of goroutines to drop back to n
+func waitForGoroutines(t *testing.T, n int) {
+	t.Helper()
+	deadline := time.Now().Add(time.Second)
+	for runtime.NumGoroutine() > n {
+		if time.Now().After(deadline) {
+			t.Errorf("Expected goroutines to finish, %d still running, started with %d", runtime.NumGoroutine(), n)
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+// newGame creates a game for the CLI to play
+func newGame(t *testing.T, cfg *hive.Config) *hive.Game {
+	t.Helper()
+	g, err := hive.New(hive.WithConfig(cfg))
+	if err != nil {
+		t.Fatalf("New() returned error: %v", err)
+	}
+	return g
+}
+
+// quickConfig is a game that plays out fast without pausing between messages
+func quickConfig() *hive.Config {
+	cfg := hive.DefaultConfig()
+	cfg.AutoRunSpeed = 0
+	cfg.RandomSeed = 12345
+	return cfg
+}
+
+func TestPlayInterrupted(t *testing.T) {
+	for _, auto := range []bool{false, true} {
+		t.Run(fmt.Sprintf("auto=%v", auto), func(t *testing.T) {
+			goroutines := runtime.NumGoroutine()
+
+			// Input that never comes, like a player who walked away
+			input, typing := io.Pipe()
+			cli := NewGameCLI(newGame(t, quickConfig()), WithAutoMode(auto))
+			cli.scanner = bufio.NewScanner(input)
+
+			ctx, cancel := context.WithCancel(context.Background())
+			done := make(chan bool)
+			go func() {
+				cli.play(ctx)
+				close(done)
+			}()
+
+			time.Sleep(50 * time.Millisecond)
+			cancel()
+			select {
+			case <-done:
+			case <-time.After(time.Second):
+				t.Fatalf("Expected play to return after cancelling")
+			}
+
+			// Only the input reader is left, until the input ends
+			typing.Close()
+			waitForGoroutines(t, goroutines)
+		})
+	}
+}
+
+func TestRunGameSigint(t *testing.T) {
+	// Catch the interrupt here too, so the test binary survives it even if
+	// the game hasn't started listening yet
+	caught := make(chan os.Signal, 1)
+	signal.Notify(caught, os.Interrupt)
+	defer signal.Stop(caught)
+
+	input, typing := io.Pipe()
+	defer typing.Close()
+	cli := NewGameCLI(newGame(t, quickConfig()), WithAutoMode(false))
+	cli.scanner = bufio.NewScanner(input)
+
+	done := make(chan bool)
+	go func() {
+		cli.runGame()
+		close(done)
+	}()
+
+	time.Sleep(50 * time.Millisecond)
+	self, _ := os.FindProcess(os.Getpid())
+	if err := self.Signal(os.Interrupt); err != nil {
+		t.Skipf("Can't send an interrupt on this platform: %v", err)
+	}
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatalf("Expected runGame to return after SIGINT")
+	}
+}
+
+func TestPlayToGameOver(t *testing.T) {
+	cfg := quickConfig()
+	cfg.Species = []hive.BeeSpecies{
+		{Name: "Queen", Amount: 1, Health: 1, HitDamage: 1, Leader: true},
+	}
+	cfg.PlayerMissChance = 0
+
+	// Enter at the game over screen exits
+	cli := NewGameCLI(newGame(t, cfg), WithAutoMode(true))
+	cli.scanner = bufio.NewScanner(strings.NewReader("\n"))
+
+	done := make(chan bool)
+	go func() {
+		cli.play(context.Background())
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(2 * time.Second):
+		t.Fatalf("Expected play to return after the game ended")
+	}
+	if state := cli.gameEngine.View().State; state != hive.PlayerWin {
+		t.Errorf("Expected the game to be won, got state %v", state)
+	}
+}
+
+func TestSaveAndLoadCommands(t *testing.T) {
+	cli := NewGameCLI(newGame(t, quickConfig()), WithAutoMode(false))
+	path := filepath.Join(t.TempDir(), "save.json")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	lines := make(chan string)
+	notes := make(chan hive.Event)
+	loads := make(chan *hive.Game, 1)
+	go cli.handleUserInput(ctx, lines, notes, loads)
+
+	tests := []struct {
+		input    string
+		expected hive.EventType
+	}{
+		{"save " + path, hive.GameSaved},
+		{"load " + path + ".missing", hive.CommandFailed},
+		{"load " + path, hive.GameLoaded},
+	}
+	for _, tt := range tests {
+		lines <- tt.input
+		if note := <-notes; note.Type != tt.expected {
+			t.Errorf("Expected %q to log %v, got %+v", tt.input, tt.expected, note)
+		}
+	}
+
+	select {
+	case loaded := <-loads:
+		if !loaded.Resumed() {
+			t.Errorf("Expected the loaded game to be resumed")
+		}
+	case <-time.After(time.Second):
+		t.Fatalf("Expected the loaded game to be handed over")
+	}
+}
@@ -1,101 +1,334 @@
 package cli
 
 import (
-	"bufio"
+	"context"
 	"fmt"
-	"os"
+	"strings"
 	"time"
 
-	"github.com/lewwolfe/beesinthetrap/internal/game"
+	"github.com/lewwolfe/beesinthetrap/pkg/hive"
 )
 
 func (c *GameCLI) displayWelcomeBanner() {
 	fmt.Println("===================================================")
 	fmt.Println("Welcome to Bees in the Trap!")
+	fmt.Println(collapseRule(c.gameEngine.View().Config))
 	fmt.Println("===================================================")
 }
 
-func (c *GameCLI) displayGameInterface() {
+// collapseRule explains when the hive collapses, naming the leader species
+func collapseRule(cfg *hive.Config) string {
+	var leaders []string
+	count := 0
+	for _, species := range cfg.Species {
+		if species.Leader && species.Amount > 0 {
+			leaders = append(leaders, species.Name)
+			count += species.Amount
+		}
+	}
+
+	if len(leaders) == 0 {
+		return "🐝 There's no queen to kill, so you'll have to kill every bee!"
+	}
+	names := strings.Join(leaders, " or ")
+
+	switch {
+	case cfg.CollapseRule == hive.CollapseNever:
+		return fmt.Sprintf("🐝 The hive won't collapse when a %s dies, you'll have to kill every bee!", names)
+	case cfg.CollapseRule == hive.CollapseLast && count > 1:
+		return fmt.Sprintf("👑 Kill all %d of the %s bees and the hive collapses!", count, names)
+	}
+	return fmt.Sprintf("👑 Kill the %s and the hive collapses!", names)
+}
+
+// formatEvent renders a game event as a line for the game log
+func formatEvent(e hive.Event) string {
+	switch e.Type {
+	case hive.PlayerPrompt:
+		return "Your move: 'hit' (or 'hit <type>' / 'hit <number>' to aim), 'defend', 'dodge', 'heal', 'use <item>', 'special' or 'flee'..."
+	case hive.InvalidCommand:
+		if e.Err != "" {
+			return fmt.Sprintf("Invalid command! '%s' (%s)", e.Input, e.Err)
+		}
+		return fmt.Sprintf("Invalid command! '%s'", e.Input)
+	case hive.PlayerMissed:
+		return "❌ Miss! You just missed the hive, better luck next time!"
+	case hive.BeeHit:
+		if e.Critical {
+			return fmt.Sprintf("💥 Critical Hit! %s #%d took %d damage, %d HP left.", e.BeeType, e.BeeID, e.Damage, e.HP)
+		}
+		return fmt.Sprintf("🧑 Direct Hit! %s #%d took %d damage, %d HP left.", e.BeeType, e.BeeID, e.Damage, e.HP)
+	case hive.BeeKilled:
+		return fmt.Sprintf("💀 You killed %s #%d!", e.BeeType, e.BeeID)
+	case hive.HiveCollapsed:
+		return fmt.Sprintf("🎉 The %s Bee is dead, and the entire hive collapses!", e.BeeType)
+	case hive.BeeStung:
+		if e.Critical {
+			return fmt.Sprintf("💥 Ouch! %s #%d landed a critical sting for %d damage!", e.BeeType, e.BeeID, e.Damage)
+		}
+		if e.Blocked > 0 {
+			return fmt.Sprintf("🛡️ %s #%d stung you for %d damage, you blocked %d!", e.BeeType, e.BeeID, e.Damage, e.Blocked)
+		}
+		return fmt.Sprintf("🐝 Ouch! %s #%d stung you for %d damage!", e.BeeType, e.BeeID, e.Damage)
+	case hive.BeeMissed:
+		return fmt.Sprintf("❌ Buzz! That was close! %s #%d just missed you!", e.BeeType, e.BeeID)
+	case hive.PlayerDefended:
+		return "🛡️ You brace yourself against the next sting."
+	case hive.PlayerDodged:
+		return "💨 You get ready to dodge the next sting."
+	case hive.PlayerHealed:
+		return fmt.Sprintf("💚 You healed %d HP, you now have %d HP.", e.Healed, e.HP)
+	case hive.PlayerRetreated:
+		return "🏃 You turn and run from the hive!"
+	case hive.ItemUsed:
+		if e.Turns > 0 {
+			return fmt.Sprintf("🧰 You used %s, it lasts %d turns.", e.Item, e.Turns)
+		}
+		return fmt.Sprintf("🧰 You used %s!", e.Item)
+	case hive.AbilityUsed:
+		if e.Turns > 0 {
+			return fmt.Sprintf("✨ You use your %s ability, it lasts %d turns.", e.Ability, e.Turns)
+		}
+		return fmt.Sprintf("✨ You use your %s ability!", e.Ability)
+	case hive.AbilityReady:
+		return fmt.Sprintf("🔋 Your %s ability is ready again.", e.Ability)
+	case hive.BeesPacified:
+		return "😴 The bees are too calm to attack."
+	case hive.BeeHealed:
+		return fmt.Sprintf("🩹 %s #%d healed %s #%d for %d HP, %d HP left.", e.BeeType, e.BeeID, e.OtherType, e.OtherID, e.Healed, e.HP)
+	case hive.BeeGuarded:
+		return fmt.Sprintf("🛡️ %s #%d threw itself in front of %s #%d!", e.BeeType, e.BeeID, e.OtherType, e.OtherID)
+	case hive.SwarmAttacked:
+		return fmt.Sprintf("🐝🐝 The swarm stung you %d times for %d damage, %d HP left.", e.Count, e.Damage, e.HP)
+	case hive.StatusApplied:
+		if e.Status == hive.Enraged {
+			return fmt.Sprintf("😡 %d %s bees are enraged by the loss of one of their own!", e.Count, e.BeeType)
+		}
+		return fmt.Sprintf("%s The sting leaves you %s!", statusGlyphs[e.Status], formatStatus(hive.Status{Kind: e.Status, Turns: e.Turns, Stacks: e.Stacks}))
+	case hive.StatusDamage:
+		return fmt.Sprintf("🤢 Poison deals %d damage, %d HP left.", e.Damage, e.HP)
+	case hive.StatusExpired:
+		return fmt.Sprintf("✨ You are no longer %s.", e.Status)
+	case hive.PlayerStunned:
+		return "💫 You're stunned and miss your turn!"
+	case hive.BeeSpawned:
+		return fmt.Sprintf("🥚 A new %s #%d hatched and joined the hive!", e.BeeType, e.BeeID)
+	case hive.GameOver:
+		switch e.State {
+		case hive.PlayerLose:
+			return "💀 You have been defeated by the hive!"
+		case hive.PlayerFled:
+			return "🏃 You escaped the hive, live to fight another day!"
+		}
+		return "🏆 Congratulations! You've destroyed the entire hive!"
+	case hive.GameSaved:
+		return fmt.Sprintf("💾 Game saved to %s", e.Path)
+	case hive.GameLoaded:
+		return fmt.Sprintf("📂 Game loaded from %s", e.Path)
+	case hive.CommandFailed:
+		return fmt.Sprintf("⚠️ Could not %s: %s", e.Input, e.Err)
+	}
+	return ""
+}
+
+func (c *GameCLI) displayGameInterface(view *hive.View) {
 	fmt.Println("===================================================")
 	fmt.Printf("Player: %s\n", c.playerName)
-	fmt.Printf("Health: %d/%d\n\n", c.gameEngine.GetPlayer().GetHP(), c.gameEngine.Config.PlayerHealth)
-	c.printRemainingBee()
+	fmt.Printf("Health: %d/%d\n", view.Player.HP, view.Player.MaxHP)
+	fmt.Printf("Heals left: %d\n", view.Player.HealsLeft)
+	printClass(view.Player)
+	if loadout := view.Player.Loadout; loadout.Name != "" {
+		fmt.Printf("Weapon: %s | Armor: %s\n", loadout.Weapon.Name, loadout.Armor.Name)
+	}
+	printStatuses(view.Player.Statuses)
+	printItems(view)
+	fmt.Println()
+	printRemainingBee(view)
 	fmt.Println("===================================================")
 	fmt.Println("GAME LOG:")
 }
 
 func (c *GameCLI) displayMessage() {
+	// Render from a published view, as the engine carries on in its own
+	// goroutine
+	view := c.gameEngine.View()
+
 	c.clearScreen()
-	c.displayGameInterface()
+	c.displayGameInterface(view)
 
 	// Filter log view to only show config amount
 	viewLogs := c.gameLogs
-	if len(c.gameLogs) > c.gameEngine.Config.LogSize {
-		viewLogs = c.gameLogs[len(c.gameLogs)-c.gameEngine.Config.LogSize:]
+	if len(c.gameLogs) > view.Config.LogSize {
+		viewLogs = c.gameLogs[len(c.gameLogs)-view.Config.LogSize:]
 	}
 
 	for _, msg := range viewLogs {
 		fmt.Println(msg)
 	}
 
-	time.Sleep(time.Duration(c.gameEngine.Config.AutoRunSpeed) * time.Second)
+	time.Sleep(time.Duration(view.Config.AutoRunSpeed) * time.Second)
 }
 
-func (c *GameCLI) displayGameOver(state game.GameState) {
+func (c *GameCLI) displayGameOver(ctx context.Context, state hive.State, lines <-chan string) {
+	view := c.gameEngine.View()
+
 	c.clearScreen()
 	fmt.Println("===================================================")
 	fmt.Println("                     GAME OVER                    ")
 	fmt.Println("===================================================")
 
-	if state == game.PlayerLose {
+	if state == hive.PlayerLose {
 		fmt.Printf("Sorry %s, you were defeated by the hive!\n", c.playerName)
-	} else if state == game.PlayerWin {
+	} else if state == hive.PlayerWin {
 		fmt.Printf("Congratulations %s! You defeated the hive!\n", c.playerName)
+	} else if state == hive.PlayerFled {
+		fmt.Printf("%s ran from the hive, the bees will remember this!\n", c.playerName)
 	}
 
 	fmt.Printf("\nFinal Stats for %s:\n", c.playerName)
-	fmt.Printf("Health remaining: %d/%d\n\n", c.gameEngine.GetPlayer().GetHP(), c.gameEngine.Config.PlayerHealth)
-	fmt.Printf("Bee Stings: %d\n", c.gameEngine.BeeStings)
-	fmt.Printf("Player Hits: %d\n\n", c.gameEngine.PlayerHits)
+	stats := view.Stats
+	fmt.Printf("Health remaining: %d/%d\n\n", view.Player.HP, view.Player.MaxHP)
+	fmt.Printf("Bee Stings: %d\n", stats.BeeStings)
+	fmt.Printf("Player Hits: %d\n", stats.PlayerHits)
+	fmt.Printf("Defends: %d (%d damage blocked)\n", stats.PlayerDefends, stats.DamageBlocked)
+	fmt.Printf("Dodges: %d\n", stats.PlayerDodges)
+	fmt.Printf("Heals used: %d\n", stats.PlayerHeals)
+	fmt.Printf("Items used: %d\n", stats.ItemsUsed)
+	fmt.Printf("Abilities used: %d\n", stats.AbilitiesUsed)
+	fmt.Printf("Poison damage taken: %d\n", stats.PoisonDamage)
+	fmt.Printf("Turns stunned: %d\n", stats.TurnsStunned)
+	fmt.Printf("Bees enraged: %d\n\n", stats.BeesEnraged)
 
-	if len(c.gameEngine.GetHive()) > 0 {
-		c.printRemainingBee()
+	if len(view.Hive) > 0 {
+		printRemainingBee(view)
 	}
 
 	fmt.Println("\n===================================================")
 	fmt.Println("Thanks for playing!")
 	fmt.Println("Press Enter to exit...")
-	bufio.NewReader(os.Stdin).ReadBytes('\n')
+	select {
+	case <-lines:
+	case <-ctx.Done():
+	}
+}
+
+// printStatuses lists the status effects the player is under, if any
+func printStatuses(statuses []hive.Status) {
+	if len(statuses) == 0 {
+		return
+	}
+
+	effects := make([]string, 0, len(statuses))
+	for _, st := range statuses {
+		effects = append(effects, formatStatus(st))
+	}
+	fmt.Printf("Status: %s\n", strings.Join(effects, ", "))
+}
+
+var statusGlyphs = map[hive.StatusKind]string{
+	hive.Poisoned: "🤢",
+	hive.Stunned:  "💫",
+	hive.Enraged:  "😡",
+}
+
+// formatStatus renders a status effect such as "poisoned x2 (3 turns)"
+func formatStatus(st hive.Status) string {
+	name := string(st.Kind)
+	if st.Stacks > 1 {
+		name = fmt.Sprintf("%s x%d", name, st.Stacks)
+	}
+	if st.Turns == 1 {
+		return name + " (1 turn)"
+	}
+	return fmt.Sprintf("%s (%d turns)", name, st.Turns)
 }
 
-func (c *GameCLI) printRemainingBee() {
+// printClass shows the player's class and whether their ability is ready
+func printClass(player hive.PlayerView) {
+	class := player.Class
+	switch {
+	case class.Name == "":
+		return
+	case class.Ability.Name == "":
+		fmt.Printf("Class: %s\n", class.Name)
+	case player.Cooldown > 0:
+		fmt.Printf("Class: %s | Special: %s (%d turns)\n", class.Name, class.Ability.Name, player.Cooldown)
+	default:
+		fmt.Printf("Class: %s | Special: %s (ready)\n", class.Name, class.Ability.Name)
+	}
+}
+
+// printItems lists the player's items in the order they are configured
+func printItems(view *hive.View) {
+	if len(view.Config.Items) == 0 {
+		return
+	}
+
+	items := make([]string, 0, len(view.Config.Items))
+	for _, item := range view.Config.Items {
+		items = append(items, fmt.Sprintf("%s x%d", item.Name, view.Player.Items[item.Name]))
+	}
+	fmt.Printf("Items: %s\n", strings.Join(items, ", "))
+}
+
+// Most bees of one species to list in full before summarising the rest by
+// ID, so a hive that keeps growing doesn't push the game log off the screen
+const maxListedBees = 12
+
+func printRemainingBee(view *hive.View) {
 	fmt.Println("Bees remaining:")
 
-	// Define the fixed order of bee types
-	beeOrder := []string{"Queen", "Worker", "Drone"}
 	beeCount := map[string]int{}
-	beeHPs := map[string][]int{}
+	beeHPs := map[string][]string{}
+	beeIDs := map[string][]int{}
 
-	// Count bees and track HPs
-	for _, bee := range c.gameEngine.GetHive() {
-		beeType := bee.GetBeeType().String()
+	// Count bees and track HPs along with the ID used to target them, marking
+	// any that are enraged
+	for _, bee := range view.Hive {
+		beeType := bee.Type.String()
 		beeCount[beeType]++
-		beeHPs[beeType] = append(beeHPs[beeType], bee.GetHP())
-	}
-
-	// Print bees in the fixed order
-	for _, beeType := range beeOrder {
-		if count, exists := beeCount[beeType]; exists {
-			fmt.Printf("%s: %d [", beeType, count)
-			for i, hp := range beeHPs[beeType] {
-				if i > 0 {
-					fmt.Print(", ")
-				}
-				fmt.Printf("%d", hp)
-			}
-			fmt.Println("]")
+		entry := fmt.Sprintf("#%d %d", bee.ID, bee.HP)
+		if bee.HasStatus(hive.Enraged) {
+			entry += "😡"
+		}
+		beeHPs[beeType] = append(beeHPs[beeType], entry)
+		beeIDs[beeType] = append(beeIDs[beeType], bee.ID)
+	}
+
+	// Print bees in the order the species are configured
+	for _, species := range view.Config.Species {
+		if count, exists := beeCount[species.Name]; exists {
+			fmt.Printf("%s %s: %d [%s]\n", species.Glyph, species.Name, count, listBees(beeHPs[species.Name], beeIDs[species.Name]))
+		}
+	}
+}
+
+// listBees joins up the bees of a species. Any past maxListedBees are only
+// listed by their IDs, which are still needed to target them.
+func listBees(bees []string, ids []int) string {
+	if len(bees) <= maxListedBees {
+		return strings.Join(bees, ", ")
+	}
+	return fmt.Sprintf("%s, ... %d more: %s", strings.Join(bees[:maxListedBees], ", "), len(bees)-maxListedBees, idRanges(ids[maxListedBees:]))
+}
+
+// idRanges lists bee IDs compactly, with runs of IDs such as 13, 14 and 15
+// written as "#13-15"
+func idRanges(ids []int) string {
+	ranges := []string{}
+	for start := 0; start < len(ids); {
+		end := start
+		for end+1 < len(ids) && ids[end+1] == ids[end]+1 {
+			end++
+		}
+		if end == start {
+			ranges = append(ranges, fmt.Sprintf("#%d", ids[start]))
+		} else {
+			ranges = append(ranges, fmt.Sprintf("#%d-%d", ids[start], ids[end]))
 		}
+		start = end + 1
 	}
+	return strings.Join(ranges, ", ")
 }
 
 func (c *GameCLI) clearScreen() {
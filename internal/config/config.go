@@ -1,89 +1,475 @@
 package config
 
 import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
 	"os"
+	"path/filepath"
 	"strconv"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
 )
 
 type Config struct {
-	PlayerHealth          int
-	LogSize               int
-	AutoRunSpeed          int
-	RandomSeed            int64
-	PlayerMissChance      float64
-	BeeMissChance         float64
-	QueenBeeAmount        int
-	QueenBeeHealth        int
-	QueenBeeAttackDamage  int
-	QueenBeeHitDamage     int
-	WorkerBeeAmount       int
-	WorkerBeeHealth       int
-	WorkerBeeAttackDamage int
-	WorkerBeeHitDamage    int
-	DroneBeeAmount        int
-	DroneBeeHealth        int
-	DroneBeeAttackDamage  int
-	DroneBeeHitDamage     int
-}
-
-func LoadConfig() *Config {
-	config := new(Config)
-
-	// Player
-	config.PlayerHealth = getEnvAsInt("PLAYER_HEALTH", 100)
-
-	//Game Options
-	config.RandomSeed = int64(getEnvAsInt("RANDOM_SEED", 0))
-	config.PlayerMissChance = getEnvAsFloat("PLAYER_MISS_CHANCE", 0.1)
-	config.BeeMissChance = getEnvAsFloat("BEE_MISS_CHANCE", 0.2)
-	config.LogSize = getEnvAsInt("LOG_SIZE", 10)
-	config.AutoRunSpeed = getEnvAsInt("AUTO_RUN_SPEED", 1)
-
-	// Queen Bee
-	config.QueenBeeAmount = getEnvAsInt("QUEEN_BEE_AMOUNT", 1)
-	config.QueenBeeHealth = getEnvAsInt("QUEEN_BEE_HEALTH", 100)
-	config.QueenBeeAttackDamage = getEnvAsInt("QUEEN_BEE_ATTACK_DAMAGE", 10)
-	config.QueenBeeHitDamage = getEnvAsInt("QUEEN_BEE_DEFENSE_DAMAGE", 10)
-
-	// Worker Bee
-	config.WorkerBeeAmount = getEnvAsInt("WORKER_BEE_AMOUNT", 5)
-	config.WorkerBeeHealth = getEnvAsInt("WORKER_BEE_HEALTH", 75)
-	config.WorkerBeeAttackDamage = getEnvAsInt("WORKER_BEE_ATTACK_DAMAGE", 5)
-	config.WorkerBeeHitDamage = getEnvAsInt("WORKER_BEE_DEFENSE_DAMAGE", 25)
-
-	// Drone Bee
-	config.DroneBeeAmount = getEnvAsInt("DRONE_BEE_AMOUNT", 25)
-	config.DroneBeeHealth = getEnvAsInt("DRONE_BEE_HEALTH", 60)
-	config.DroneBeeAttackDamage = getEnvAsInt("DRONE_BEE_ATTACK_DAMAGE", 1)
-	config.DroneBeeHitDamage = getEnvAsInt("DRONE_BEE_DEFENSE_DAMAGE", 30)
-
-	return config
-}
-
-func getEnvAsInt(key string, defaultVal int) int {
-	value, exists := os.LookupEnv(key)
-	if !exists {
-		return defaultVal
+	Profile              string       `json:"profile"`
+	PlayerHealth         int          `json:"player_health"`
+	LogSize              int          `json:"log_size"`
+	AutoRunSpeed         int          `json:"auto_run_speed"`
+	RandomSeed           int64        `json:"random_seed"`
+	PlayerMissChance     float64      `json:"player_miss_chance"`
+	PlayerCritChance     float64      `json:"player_crit_chance"`
+	PlayerCritMultiplier float64      `json:"player_crit_multiplier"`
+	AimedMissPenalty     float64      `json:"aimed_miss_penalty"`
+	DefendReduction      float64      `json:"defend_reduction"`
+	DodgeBonus           float64      `json:"dodge_bonus"`
+	HealAmount           int          `json:"heal_amount"`
+	HealUses             int          `json:"heal_uses"`
+	CollapseRule         string       `json:"collapse_rule"` // when leader deaths collapse the hive, one of the Collapse* values
+	MaxHiveSize          int          `json:"max_hive_size"` // bees stop spawning at this size, 0 for no limit
+	SwarmMode            string       `json:"swarm_mode"`    // how many bees act on the bee turn, one of the Swarm* values
+	SwarmSize            int          `json:"swarm_size"`
+	SwarmRatio           float64      `json:"swarm_ratio"`
+	Poison               StatusEffect `json:"poison"`
+	Stun                 StatusEffect `json:"stun"`
+	Enrage               StatusEffect `json:"enrage"`
+	Species              []BeeSpecies `json:"species"`
+	Items                []Item       `json:"items"`
+	Loadouts             []Loadout    `json:"loadouts"`
+	Loadout              string       `json:"loadout"` // name of the chosen loadout, the first one if not set
+	Classes              []Class      `json:"classes"`
+	Class                string       `json:"class"` // name of the chosen class, the first one if not set
+}
+
+// BeeSpecies defines one type of bee in the hive. Species are shown and
+// spawned in the order they are listed.
+type BeeSpecies struct {
+	Name            string  `json:"name"`
+	Amount          int     `json:"amount"`
+	Health          int     `json:"health"`
+	AttackDamage    int     `json:"attack_damage"`
+	AttackDamageMax int     `json:"attack_damage_max"` // stings roll between AttackDamage and this, when set
+	HitDamage       int     `json:"hit_damage"`
+	HitDamageMax    int     `json:"hit_damage_max"` // hits roll between HitDamage and this, when set
+	MissChance      float64 `json:"miss_chance"`
+	CritChance      float64 `json:"crit_chance"`
+	CritMultiplier  float64 `json:"crit_multiplier"`
+	TargetWeight    float64 `json:"target_weight"`  // how likely a bee is to be hit compared to others, 0 is the same as 1
+	AttackWeight    float64 `json:"attack_weight"`  // how likely a bee is to attack compared to others, 0 is the same as 1
+	PoisonChance    float64 `json:"poison_chance"`  // chance of a sting poisoning the player
+	StunChance      float64 `json:"stun_chance"`    // chance of a sting stunning the player
+	Leader          bool    `json:"leader"`         // the hive collapses when a leader dies
+	Role            string  `json:"role"`           // what the bee does on its turn, one of the Role* values
+	HealAmount      int     `json:"heal_amount"`    // HP a healer restores to another bee
+	GuardChance     float64 `json:"guard_chance"`   // chance of a guard taking a hit aimed at a leader
+	Spawns          string  `json:"spawns"`         // species of bee this one lays, if any
+	SpawnInterval   int     `json:"spawn_interval"` // number of turns between each egg
+	Glyph           string  `json:"glyph"`
+}
+
+// StatusEffect sets how long a status effect lasts and how strong it is. The
+// same effect applied again adds a stack, up to MaxStacks.
+type StatusEffect struct {
+	Turns     int     `json:"turns"`
+	MaxStacks int     `json:"max_stacks"`
+	Damage    int     `json:"damage"` // damage each turn per stack, for poison
+	Bonus     float64 `json:"bonus"`  // extra sting damage per stack, for enrage
+}
+
+// Loadout is a weapon and armor the player can choose to start the game with
+type Loadout struct {
+	Name   string `json:"name"`
+	Weapon Weapon `json:"weapon"`
+	Armor  Armor  `json:"armor"`
+}
+
+// Weapon adds to the damage of the player's hits and changes their accuracy
+type Weapon struct {
+	Name     string             `json:"name"`
+	Damage   int                `json:"damage"`   // added to the damage of every hit
+	Accuracy float64            `json:"accuracy"` // taken off the player's miss chance, negative for clumsy weapons
+	Bonus    map[string]float64 `json:"bonus"`    // damage multiplier against bee species, e.g. {"Queen": 1.5}
+}
+
+// Armor takes damage off every sting, first a flat amount then a share of
+// what is left
+type Armor struct {
+	Name      string  `json:"name"`
+	Flat      int     `json:"flat"`
+	Reduction float64 `json:"reduction"`
+}
+
+// Class changes the player's stats on top of the player settings and gives
+// them a special ability
+type Class struct {
+	Name        string  `json:"name"`
+	Description string  `json:"description"`
+	Health      int     `json:"health"`   // added to the player's health, negative for frail classes
+	Damage      int     `json:"damage"`   // added to the damage of every hit
+	Accuracy    float64 `json:"accuracy"` // taken off the player's miss chance
+	Dodge       float64 `json:"dodge"`    // added to the bees' miss chance on every sting
+	Ability     Ability `json:"ability"`
+}
+
+// Ability is a class's special action, used with "special". It has the same
+// effects as items, and can be used again once Cooldown player turns pass.
+type Ability struct {
+	Name     string  `json:"name"`
+	Effect   string  `json:"effect"`
+	Cooldown int     `json:"cooldown"`
+	Amount   int     `json:"amount"`
+	Targets  int     `json:"targets"`
+	Turns    int     `json:"turns"`
+	Resist   float64 `json:"resist"`
+}
+
+// Bee roles
+const (
+	RoleStinger = "stinger" // stings the player, the default
+	RoleHealer  = "healer"  // heals the most damaged bee, leaders first, or stings when none are hurt
+	RoleGuard   = "guard"   // stings the player, and may take hits aimed at a leader
+)
+
+// Hive collapse rules
+const (
+	CollapseFirst = "first" // the hive collapses when any leader dies
+	CollapseLast  = "last"  // the hive collapses when the last leader dies
+	CollapseNever = "never" // every bee has to be killed
+)
+
+// Swarm modes
+const (
+	SwarmFixed        = "fixed"        // SwarmSize bees act each bee turn
+	SwarmProportional = "proportional" // SwarmRatio of the hive acts, at least one bee
+	SwarmPerType      = "per_type"     // one bee of each species acts
+)
+
+// Item and ability effects
+const (
+	EffectPacify     = "pacify"      // the bees don't attack for Turns bee turns
+	EffectAreaDamage = "area_damage" // deals Amount damage to up to Targets random bees
+	EffectHeal       = "heal"        // restores Amount HP and resists stings for Turns bee turns
+	EffectEvade      = "evade"       // the player dodges every sting for Turns bee turns
+)
+
+// Item is a consumable the player starts the game with, used with "use <name>"
+type Item struct {
+	Name     string  `json:"name"`
+	Effect   string  `json:"effect"`
+	Quantity int     `json:"quantity"` // how many the player starts with
+	Amount   int     `json:"amount"`
+	Targets  int     `json:"targets"`
+	Turns    int     `json:"turns"`
+	Resist   float64 `json:"resist"` // share of sting damage resisted by a heal item
+}
+
+// setting ties a config value to the env var and command line flag that
+// override it. value returns a pointer to the field within the config.
+type setting struct {
+	env   string
+	flag  string
+	usage string
+	value func(c *Config) any
+}
+
+var settings = []setting{
+	{"PLAYER_HEALTH", "player-health", "player starting health", func(c *Config) any { return &c.PlayerHealth }},
+	{"LOG_SIZE", "log-size", "number of lines of game log to show", func(c *Config) any { return &c.LogSize }},
+	{"AUTO_RUN_SPEED", "auto-run-speed", "seconds between game log messages", func(c *Config) any { return &c.AutoRunSpeed }},
+	{"RANDOM_SEED", "seed", "random seed, 0 for a random game", func(c *Config) any { return &c.RandomSeed }},
+	{"PLAYER_MISS_CHANCE", "player-miss-chance", "chance of the player missing, 0 to 1", func(c *Config) any { return &c.PlayerMissChance }},
+	{"PLAYER_CRIT_CHANCE", "player-crit-chance", "chance of a player critical hit, 0 to 1", func(c *Config) any { return &c.PlayerCritChance }},
+	{"PLAYER_CRIT_MULTIPLIER", "player-crit-multiplier", "damage multiplier of player critical hits", func(c *Config) any { return &c.PlayerCritMultiplier }},
+	{"AIMED_MISS_PENALTY", "aimed-miss-penalty", "extra miss chance for aimed shots, 0 to 1", func(c *Config) any { return &c.AimedMissPenalty }},
+	{"DEFEND_REDUCTION", "defend-reduction", "share of sting damage blocked when defending, 0 to 1", func(c *Config) any { return &c.DefendReduction }},
+	{"DODGE_BONUS", "dodge-bonus", "extra bee miss chance when dodging, 0 to 1", func(c *Config) any { return &c.DodgeBonus }},
+	{"HEAL_AMOUNT", "heal-amount", "HP restored by each heal", func(c *Config) any { return &c.HealAmount }},
+	{"HEAL_USES", "heal-uses", "number of heals per game", func(c *Config) any { return &c.HealUses }},
+	{"COLLAPSE_RULE", "collapse-rule", "when the hive collapses: first or last leader death, or never", func(c *Config) any { return &c.CollapseRule }},
+	{"SWARM_MODE", "swarm-mode", "how many bees attack each turn: fixed, proportional or per_type", func(c *Config) any { return &c.SwarmMode }},
+	{"SWARM_SIZE", "swarm-size", "number of bees attacking each turn in fixed swarm mode", func(c *Config) any { return &c.SwarmSize }},
+	{"SWARM_RATIO", "swarm-ratio", "share of the hive attacking each turn in proportional swarm mode, 0 to 1", func(c *Config) any { return &c.SwarmRatio }},
+	{"POISON_TURNS", "poison-turns", "turns poison lasts", func(c *Config) any { return &c.Poison.Turns }},
+	{"POISON_MAX_STACKS", "poison-max-stacks", "most times poison can stack", func(c *Config) any { return &c.Poison.MaxStacks }},
+	{"POISON_DAMAGE", "poison-damage", "poison damage each turn per stack", func(c *Config) any { return &c.Poison.Damage }},
+	{"STUN_TURNS", "stun-turns", "turns a stun lasts", func(c *Config) any { return &c.Stun.Turns }},
+	{"ENRAGE_TURNS", "enrage-turns", "turns bees stay enraged after one of their kind dies, 0 to turn off", func(c *Config) any { return &c.Enrage.Turns }},
+	{"ENRAGE_MAX_STACKS", "enrage-max-stacks", "most times enrage can stack", func(c *Config) any { return &c.Enrage.MaxStacks }},
+	{"ENRAGE_BONUS", "enrage-bonus", "extra sting damage per enrage stack, 0.5 is 50%", func(c *Config) any { return &c.Enrage.Bonus }},
+	{"CLASS", "class", "name of the player class, skips the class prompt", func(c *Config) any { return &c.Class }},
+	{"LOADOUT", "loadout", "name of the starting weapon and armor loadout, skips the loadout prompt", func(c *Config) any { return &c.Loadout }},
+	{"MAX_HIVE_SIZE", "max-hive-size", "most bees the hive can grow to, 0 for no limit", func(c *Config) any { return &c.MaxHiveSize }},
+}
+
+// speciesSettings are overridden for all species with BEE_<KEY>, or for one
+// species with <NAME>_BEE_<KEY>, so QUEEN_BEE_HEALTH sets the health of the
+// Queen species. On the command line the same is --bee queen.health=100.
+var speciesSettings = []struct {
+	key   string
+	value func(s *BeeSpecies) any
+}{
+	{"AMOUNT", func(s *BeeSpecies) any { return &s.Amount }},
+	{"HEALTH", func(s *BeeSpecies) any { return &s.Health }},
+	{"ATTACK_DAMAGE", func(s *BeeSpecies) any { return &s.AttackDamage }},
+	{"ATTACK_DAMAGE_MAX", func(s *BeeSpecies) any { return &s.AttackDamageMax }},
+	{"DEFENSE_DAMAGE", func(s *BeeSpecies) any { return &s.HitDamage }},
+	{"DEFENSE_DAMAGE_MAX", func(s *BeeSpecies) any { return &s.HitDamageMax }},
+	{"MISS_CHANCE", func(s *BeeSpecies) any { return &s.MissChance }},
+	{"CRIT_CHANCE", func(s *BeeSpecies) any { return &s.CritChance }},
+	{"CRIT_MULTIPLIER", func(s *BeeSpecies) any { return &s.CritMultiplier }},
+	{"TARGET_WEIGHT", func(s *BeeSpecies) any { return &s.TargetWeight }},
+	{"ATTACK_WEIGHT", func(s *BeeSpecies) any { return &s.AttackWeight }},
+	{"POISON_CHANCE", func(s *BeeSpecies) any { return &s.PoisonChance }},
+	{"STUN_CHANCE", func(s *BeeSpecies) any { return &s.StunChance }},
+	{"ROLE", func(s *BeeSpecies) any { return &s.Role }},
+	{"HEAL_AMOUNT", func(s *BeeSpecies) any { return &s.HealAmount }},
+	{"GUARD_CHANCE", func(s *BeeSpecies) any { return &s.GuardChance }},
+	{"SPAWNS", func(s *BeeSpecies) any { return &s.Spawns }},
+	{"SPAWN_INTERVAL", func(s *BeeSpecies) any { return &s.SpawnInterval }},
+}
+
+// itemSettings are overridden for one item with <NAME>_ITEM_<KEY>, so
+// SMOKE_ITEM_QUANTITY sets how much smoke the player starts with. On the
+// command line the same is --item smoke.quantity=2.
+var itemSettings = []struct {
+	key   string
+	value func(i *Item) any
+}{
+	{"QUANTITY", func(i *Item) any { return &i.Quantity }},
+	{"AMOUNT", func(i *Item) any { return &i.Amount }},
+	{"TARGETS", func(i *Item) any { return &i.Targets }},
+	{"TURNS", func(i *Item) any { return &i.Turns }},
+	{"RESIST", func(i *Item) any { return &i.Resist }},
+}
+
+// LoadConfig builds the config in layers: the defaults of the difficulty
+// profile, then the config file at path (if any), then environment variables.
+// The profile comes from the profile argument, the DIFFICULTY env var or the
+// config file, in that order. The result is validated before it is returned.
+func LoadConfig(path, profile string) (*Config, error) {
+	return load(path, profile, nil)
+}
+
+// load is LoadConfig with command line flags applied after the env vars
+func load(path, profile string, flags *Flags) (*Config, error) {
+	var file []byte
+	if path != "" {
+		var err error
+		if file, err = readConfigFile(path); err != nil {
+			return nil, err
+		}
 	}
 
-	intValue, err := strconv.Atoi(value)
+	// Work out the profile before applying anything on top of it
+	if profile == "" {
+		profile = os.Getenv("DIFFICULTY")
+	}
+	if profile == "" && file != nil {
+		var header struct {
+			Profile string `json:"profile"`
+		}
+		if err := json.Unmarshal(file, &header); err != nil {
+			return nil, fmt.Errorf("decoding config %s: %w", path, err)
+		}
+		profile = header.Profile
+	}
+
+	config, err := ProfileConfig(profile)
+	if err != nil {
+		return nil, err
+	}
+
+	if file != nil {
+		// Lists in the file replace the defaults rather than being decoded
+		// over the top of them entry by entry
+		defaults := *config
+		config.Species, config.Items, config.Loadouts, config.Classes = nil, nil, nil, nil
+		if err := decodeStrict(file, config); err != nil {
+			return nil, fmt.Errorf("decoding config %s: %w", path, err)
+		}
+		config.Profile = defaults.Profile
+		if config.Species == nil {
+			config.Species = defaults.Species
+		}
+		if config.Items == nil {
+			config.Items = defaults.Items
+		}
+		if config.Loadouts == nil {
+			config.Loadouts = defaults.Loadouts
+		}
+		if config.Classes == nil {
+			config.Classes = defaults.Classes
+		}
+	}
+
+	if err := config.applyEnv(); err != nil {
+		return nil, err
+	}
+
+	if flags != nil {
+		if err := flags.apply(config); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+	return config, nil
+}
+
+// readConfigFile reads a JSON, YAML or TOML config file. YAML and TOML are
+// turned into JSON, so every format uses the same keys and is decoded the
+// same way.
+func readConfigFile(path string) ([]byte, error) {
+	var decode func(data []byte, v any) error
+	switch ext := strings.ToLower(filepath.Ext(path)); ext {
+	case ".json":
+	case ".yaml", ".yml":
+		decode = yaml.Unmarshal
+	case ".toml":
+		decode = toml.Unmarshal
+	default:
+		return nil, fmt.Errorf("config %s: unsupported format %q, only .json, .yaml and .toml config files are supported", path, ext)
+	}
+
+	data, err := os.ReadFile(path)
 	if err != nil {
-		return defaultVal
+		return nil, fmt.Errorf("reading config: %w", err)
+	}
+	if decode == nil {
+		return data, nil
+	}
+
+	var doc map[string]any
+	if err := decode(data, &doc); err != nil {
+		return nil, fmt.Errorf("decoding config %s: %w", path, err)
+	}
+	if data, err = json.Marshal(doc); err != nil {
+		return nil, fmt.Errorf("decoding config %s: %w", path, err)
+	}
+	return data, nil
+}
+
+// decodeStrict decodes JSON into v, returning an error naming any key v has
+// no field for, so a typo in a config file doesn't go unnoticed
+func decodeStrict(data []byte, v any) error {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(v); err != nil {
+		return err
+	}
+	if dec.More() {
+		return errors.New("unexpected data after the end of the JSON")
+	}
+	return nil
+}
+
+// applyEnv overrides config values with any env vars that are set
+func (c *Config) applyEnv() error {
+	var errs []error
+
+	for _, s := range settings {
+		if err := setFromEnv(s.env, s.value(c)); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	// Bee species can come from their own file
+	if path := os.Getenv("BEE_SPECIES_FILE"); path != "" {
+		species, err := LoadSpecies(path)
+		if err != nil {
+			errs = append(errs, err)
+		} else {
+			c.Species = species
+		}
+	}
+
+	// BEE_<KEY> applies to every species, then <NAME>_BEE_<KEY> to just one
+	for i := range c.Species {
+		for _, prefix := range []string{"BEE_", strings.ToUpper(c.Species[i].Name) + "_BEE_"} {
+			for _, s := range speciesSettings {
+				if err := setFromEnv(prefix+s.key, s.value(&c.Species[i])); err != nil {
+					errs = append(errs, err)
+				}
+			}
+		}
+	}
+
+	for i := range c.Items {
+		prefix := strings.ToUpper(c.Items[i].Name) + "_ITEM_"
+		for _, s := range itemSettings {
+			if err := setFromEnv(prefix+s.key, s.value(&c.Items[i])); err != nil {
+				errs = append(errs, err)
+			}
+		}
 	}
-	return intValue
 
+	return errors.Join(errs...)
 }
 
-func getEnvAsFloat(key string, defaultVal float64) float64 {
+// setFromEnv parses the env var into the field, if the env var is set
+func setFromEnv(key string, field any) error {
 	value, exists := os.LookupEnv(key)
 	if !exists {
-		return defaultVal
+		return nil
+	}
+	if err := setValue(field, value); err != nil {
+		return fmt.Errorf("%s: %w", key, err)
+	}
+	return nil
+}
+
+// setValue parses a string into a pointer to a config field
+func setValue(field any, value string) error {
+	value = strings.TrimSpace(value)
+
+	switch f := field.(type) {
+	case *int:
+		v, err := strconv.Atoi(value)
+		if err != nil {
+			return fmt.Errorf("%q is not a whole number", value)
+		}
+		*f = v
+	case *int64:
+		v, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return fmt.Errorf("%q is not a whole number", value)
+		}
+		*f = v
+	case *float64:
+		v, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return fmt.Errorf("%q is not a number", value)
+		}
+		*f = v
+	case *bool:
+		v, err := strconv.ParseBool(value)
+		if err != nil {
+			return fmt.Errorf("%q is not true or false", value)
+		}
+		*f = v
+	case *string:
+		*f = value
+	default:
+		return fmt.Errorf("unsupported config field type %T", field)
 	}
+	return nil
+}
 
-	floatValue, err := strconv.ParseFloat(value, 64)
+// LoadSpecies reads the bee species for the hive from a JSON file
+func LoadSpecies(path string) ([]BeeSpecies, error) {
+	data, err := os.ReadFile(path)
 	if err != nil {
-		return defaultVal
+		return nil, fmt.Errorf("reading bee species: %w", err)
 	}
-	return floatValue
 
+	var species []BeeSpecies
+	if err := decodeStrict(data, &species); err != nil {
+		return nil, fmt.Errorf("decoding bee species %s: %w", path, err)
+	}
+	return species, nil
 }
@@ -0,0 +1,307 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func writeFile(t *testing.T, name, contents string) string {
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(contents), 0644); err != nil {
+		t.Fatalf("Failed to write %s: %v", name, err)
+	}
+	return path
+}
+
+func TestLoadConfigDefaults(t *testing.T) {
+	cfg, err := LoadConfig("", "")
+	if err != nil {
+		t.Fatalf("LoadConfig() returned error: %v", err)
+	}
+
+	if cfg.Profile != DefaultProfile || cfg.PlayerHealth != 100 || len(cfg.Species) != 3 {
+		t.Errorf("Expected normal defaults, got %+v", cfg)
+	}
+}
+
+func TestLoadConfigLayers(t *testing.T) {
+	path := writeFile(t, "hive.json", `{
+		"profile": "hard",
+		"log_size": 5,
+		"species": [
+			{"name": "Queen", "amount": 1, "health": 100, "hit_damage": 10, "leader": true},
+			{"name": "Guard", "amount": 4, "health": 80, "attack_damage": 6, "hit_damage": 20, "miss_chance": 0.6}
+		]
+	}`)
+
+	// Env vars override the file
+	t.Setenv("LOG_SIZE", "7")
+	t.Setenv("GUARD_BEE_AMOUNT", "2")
+
+	cfg, err := LoadConfig(path, "")
+	if err != nil {
+		t.Fatalf("LoadConfig() returned error: %v", err)
+	}
+
+	if cfg.Profile != "hard" || cfg.PlayerHealth != 80 {
+		t.Errorf("Expected hard profile from the file, got profile %q with health %d", cfg.Profile, cfg.PlayerHealth)
+	}
+	if cfg.LogSize != 7 {
+		t.Errorf("Expected env to override log size to 7, got %d", cfg.LogSize)
+	}
+	if len(cfg.Species) != 2 || cfg.Species[1].Name != "Guard" || cfg.Species[1].Amount != 2 {
+		t.Errorf("Expected species from the file with env override, got %+v", cfg.Species)
+	}
+	if s := cfg.Species[1]; s.MissChance != 0.6 || s.CritChance != 0 {
+		t.Errorf("Expected the file's species to override the profile's, got %+v", s)
+	}
+
+	// The profile argument beats the file
+	cfg, err = LoadConfig(path, "easy")
+	if err != nil {
+		t.Fatalf("LoadConfig() returned error: %v", err)
+	}
+	if cfg.Profile != "easy" || cfg.PlayerHealth != 150 {
+		t.Errorf("Expected easy profile, got profile %q with health %d", cfg.Profile, cfg.PlayerHealth)
+	}
+}
+
+func TestLoadConfigFormats(t *testing.T) {
+	files := map[string]string{
+		"hive.json": `{
+			"profile": "hard",
+			"log_size": 5,
+			"species": [
+				{"name": "Queen", "amount": 1, "health": 100, "hit_damage": 10, "leader": true},
+				{"name": "Guard", "amount": 4, "health": 80, "attack_damage": 6, "hit_damage": 20}
+			]
+		}`,
+		"hive.yaml": `
+profile: hard
+log_size: 5
+species:
+  - {name: Queen, amount: 1, health: 100, hit_damage: 10, leader: true}
+  - {name: Guard, amount: 4, health: 80, attack_damage: 6, hit_damage: 20}
+`,
+		"hive.toml": `
+profile = "hard"
+log_size = 5
+
+[[species]]
+name = "Queen"
+amount = 1
+health = 100
+hit_damage = 10
+leader = true
+
+[[species]]
+name = "Guard"
+amount = 4
+health = 80
+attack_damage = 6
+hit_damage = 20
+`,
+	}
+
+	// Every format gives the same config
+	want, err := LoadConfig(writeFile(t, "hive.json", files["hive.json"]), "")
+	if err != nil {
+		t.Fatalf("LoadConfig() returned error: %v", err)
+	}
+	if want.LogSize != 5 || len(want.Species) != 2 || want.PlayerHealth != 80 {
+		t.Fatalf("Expected the config from the file, got %+v", want)
+	}
+	for _, name := range []string{"hive.yaml", "hive.toml"} {
+		t.Run(name, func(t *testing.T) {
+			cfg, err := LoadConfig(writeFile(t, name, files[name]), "")
+			if err != nil {
+				t.Fatalf("LoadConfig() returned error: %v", err)
+			}
+			if !reflect.DeepEqual(cfg, want) {
+				t.Errorf("Expected the same config as hive.json, got %+v", cfg)
+			}
+		})
+	}
+
+	if _, err := LoadConfig(writeFile(t, "bad.yaml", "species: [unclosed"), ""); err == nil || !strings.Contains(err.Error(), "decoding config") {
+		t.Errorf("Expected an error decoding bad YAML, got %v", err)
+	}
+}
+
+func TestLoadConfigUnknownKeys(t *testing.T) {
+	tests := []struct {
+		name     string
+		contents string
+		key      string
+	}{
+		{"hive.json", `{"player_heath": 5}`, "player_heath"},
+		{"hive.yaml", "player_heath: 5\n", "player_heath"},
+		{"hive.toml", "player_heath = 5\n", "player_heath"},
+		{"species.json", `{"species": [{"name": "Queen", "amount": 1, "health": 100, "hit_damage": 10, "mis_chance": 0.5}]}`, "mis_chance"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := LoadConfig(writeFile(t, tt.name, tt.contents), "")
+			if err == nil || !strings.Contains(err.Error(), `unknown field "`+tt.key+`"`) {
+				t.Errorf("Expected an error naming %s, got %v", tt.key, err)
+			}
+		})
+	}
+
+	// Every key in the example config is a real one
+	if _, err := LoadConfig(filepath.Join("..", "..", "config.example.json"), ""); err != nil {
+		t.Errorf("Expected the example config to load, got %v", err)
+	}
+}
+
+func TestLoadConfigErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		env     map[string]string
+		profile string
+		path    string
+		wantErr string
+	}{
+		{
+			name:    "Typo in env value",
+			env:     map[string]string{"PLAYER_HEALTH": "1OO"},
+			wantErr: `PLAYER_HEALTH: "1OO" is not a whole number`,
+		},
+		{
+			name:    "Unknown profile",
+			profile: "impossible",
+			wantErr: `unknown difficulty profile "impossible"`,
+		},
+		{
+			name:    "Unsupported file format",
+			path:    "hive.ini",
+			wantErr: "only .json, .yaml and .toml config files are supported",
+		},
+		{
+			name:    "Negative health",
+			env:     map[string]string{"WORKER_BEE_HEALTH": "-5"},
+			wantErr: "Worker bee health must be positive, got -5",
+		},
+		{
+			name:    "Miss chance out of range",
+			env:     map[string]string{"PLAYER_MISS_CHANCE": "1.5"},
+			wantErr: "player miss chance must be between 0 and 1, got 1.5",
+		},
+		{
+			name:    "No bees",
+			env:     map[string]string{"BEE_AMOUNT": "0"},
+			wantErr: "the hive must have at least one bee",
+		},
+		{
+			name:    "Unknown collapse rule",
+			env:     map[string]string{"COLLAPSE_RULE": "sometimes"},
+			wantErr: `unknown collapse rule "sometimes"`,
+		},
+		{
+			name:    "Spawns unknown species",
+			env:     map[string]string{"QUEEN_BEE_SPAWNS": "Wasp"},
+			wantErr: `Queen bee spawns unknown species "Wasp"`,
+		},
+		{
+			name:    "Poison chance out of range",
+			env:     map[string]string{"WORKER_BEE_POISON_CHANCE": "2"},
+			wantErr: "Worker bee poison chance must be between 0 and 1, got 2",
+		},
+		{
+			name:    "Unknown swarm mode",
+			env:     map[string]string{"SWARM_MODE": "everyone"},
+			wantErr: `unknown swarm mode "everyone"`,
+		},
+		{
+			name:    "Unknown role",
+			env:     map[string]string{"DRONE_BEE_ROLE": "dancer"},
+			wantErr: `Drone bee has unknown role "dancer"`,
+		},
+		{
+			name:    "Negative item quantity",
+			env:     map[string]string{"SMOKE_ITEM_QUANTITY": "-1"},
+			wantErr: "smoke quantity can't be negative, got -1",
+		},
+		{
+			name:    "Unknown class",
+			env:     map[string]string{"CLASS": "wizard"},
+			wantErr: `unknown class "wizard"`,
+		},
+		{
+			name:    "Unknown loadout",
+			env:     map[string]string{"LOADOUT": "bazooka"},
+			wantErr: `unknown loadout "bazooka"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for key, value := range tt.env {
+				t.Setenv(key, value)
+			}
+
+			_, err := LoadConfig(tt.path, tt.profile)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Expected error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestLoadConfigWithoutLeaders(t *testing.T) {
+	// A hive with no queen can't collapse, but can still be played
+	t.Setenv("QUEEN_BEE_AMOUNT", "0")
+
+	if _, err := LoadConfig("", ""); err != nil {
+		t.Errorf("Expected a hive without leaders to load, got %v", err)
+	}
+}
+
+func TestValidateReportsEveryProblem(t *testing.T) {
+	cfg := Default()
+	cfg.PlayerHealth = 0
+	cfg.Species[1].MissChance = -1
+	cfg.Species[2].Name = "Queen"
+
+	err := cfg.Validate()
+	if err == nil {
+		t.Fatal("Expected validation errors")
+	}
+
+	for _, want := range []string{"player health", "Worker bee miss chance", "Queen is defined more than once"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("Expected error to mention %q, got %v", want, err)
+		}
+	}
+}
+
+func TestProfiles(t *testing.T) {
+	for _, name := range Profiles() {
+		cfg, err := ProfileConfig(name)
+		if err != nil {
+			t.Fatalf("ProfileConfig(%q) returned error: %v", name, err)
+		}
+		if err := cfg.Validate(); err != nil {
+			t.Errorf("Profile %q is not valid: %v", name, err)
+		}
+	}
+}
+
+func TestNightmareSpawnInterval(t *testing.T) {
+	cfg := Default()
+	cfg.Species[0].SpawnInterval = 1
+	profiles["nightmare"](cfg)
+
+	if got := cfg.Species[0].SpawnInterval; got != 1 {
+		t.Errorf("Expected the spawn interval to stay at least 1, got %d", got)
+	}
+	if got := cfg.Species[1].SpawnInterval; got != 0 {
+		t.Errorf("Expected a species that doesn't spawn to keep no interval, got %d", got)
+	}
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("Expected the config to be valid, got %v", err)
+	}
+}
@@ -0,0 +1,138 @@
+package config
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"strings"
+)
+
+// Flags are config values given on the command line, which override
+// everything else
+type Flags struct {
+	path    string
+	profile string
+	values  []flagValue
+}
+
+type flagValue struct {
+	name  string
+	value string
+	set   func(c *Config, value string) error
+}
+
+// RegisterFlags adds a flag for every config value to fs. Values are checked
+// as they are parsed, and applied when the config is loaded with Load.
+func RegisterFlags(fs *flag.FlagSet) *Flags {
+	f := new(Flags)
+	fs.StringVar(&f.path, "config", "", "load config from a JSON, YAML or TOML `file`")
+	fs.StringVar(&f.profile, "profile", "", "difficulty `profile`: "+strings.Join(Profiles(), ", "))
+
+	for _, s := range settings {
+		set := func(c *Config, value string) error {
+			return setValue(s.value(c), value)
+		}
+		fs.Func(s.flag, s.usage+" (env "+s.env+")", func(value string) error {
+			// Check the value parses now, so mistakes show up with the usage
+			if err := set(new(Config), value); err != nil {
+				return err
+			}
+			f.values = append(f.values, flagValue{name: s.flag, value: value, set: set})
+			return nil
+		})
+	}
+
+	fs.Func("bee", "set a bee species value as `species.key=value`, e.g. queen.health=150", func(value string) error {
+		set, err := speciesFlag(value)
+		if err != nil {
+			return err
+		}
+		f.values = append(f.values, flagValue{name: "bee", value: value, set: set})
+		return nil
+	})
+
+	fs.Func("item", "set an item value as `item.key=value`, e.g. smoke.quantity=2", func(value string) error {
+		set, err := itemFlag(value)
+		if err != nil {
+			return err
+		}
+		f.values = append(f.values, flagValue{name: "item", value: value, set: set})
+		return nil
+	})
+
+	return f
+}
+
+// Load loads the config as LoadConfig does, then applies the flags
+func (f *Flags) Load() (*Config, error) {
+	return load(f.path, f.profile, f)
+}
+
+func (f *Flags) apply(c *Config) error {
+	var errs []error
+	for _, v := range f.values {
+		if err := v.set(c, v.value); err != nil {
+			errs = append(errs, fmt.Errorf("--%s %s: %w", v.name, v.value, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// speciesFlag parses a --bee flag into a setter for the species value
+func speciesFlag(flagValue string) (func(c *Config, value string) error, error) {
+	target, value, ok := strings.Cut(flagValue, "=")
+	name, key, ok2 := strings.Cut(target, ".")
+	if !ok || !ok2 {
+		return nil, errors.New("expected species.key=value")
+	}
+
+	key = strings.ToUpper(strings.ReplaceAll(key, "-", "_"))
+	for _, s := range speciesSettings {
+		if s.key != key {
+			continue
+		}
+
+		// Check the value parses now, the species is only known once loaded
+		if err := setValue(s.value(new(BeeSpecies)), value); err != nil {
+			return nil, err
+		}
+		return func(c *Config, _ string) error {
+			for i := range c.Species {
+				if strings.EqualFold(c.Species[i].Name, name) {
+					return setValue(s.value(&c.Species[i]), value)
+				}
+			}
+			return fmt.Errorf("unknown bee species %q", name)
+		}, nil
+	}
+	return nil, fmt.Errorf("unknown bee setting %q", key)
+}
+
+// itemFlag parses an --item flag into a setter for the item value
+func itemFlag(flagValue string) (func(c *Config, value string) error, error) {
+	target, value, ok := strings.Cut(flagValue, "=")
+	name, key, ok2 := strings.Cut(target, ".")
+	if !ok || !ok2 {
+		return nil, errors.New("expected item.key=value")
+	}
+
+	key = strings.ToUpper(strings.ReplaceAll(key, "-", "_"))
+	for _, s := range itemSettings {
+		if s.key != key {
+			continue
+		}
+
+		if err := setValue(s.value(new(Item)), value); err != nil {
+			return nil, err
+		}
+		return func(c *Config, _ string) error {
+			for i := range c.Items {
+				if strings.EqualFold(c.Items[i].Name, name) {
+					return setValue(s.value(&c.Items[i]), value)
+				}
+			}
+			return fmt.Errorf("unknown item %q", name)
+		}, nil
+	}
+	return nil, fmt.Errorf("unknown item setting %q", key)
+}
@@ -0,0 +1,72 @@
+package config
+
+import (
+	"flag"
+	"io"
+	"strings"
+	"testing"
+)
+
+func newFlagSet() (*flag.FlagSet, *Flags) {
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	return fs, RegisterFlags(fs)
+}
+
+func TestFlagsOverrideEnv(t *testing.T) {
+	t.Setenv("PLAYER_HEALTH", "50")
+	t.Setenv("QUEEN_BEE_HEALTH", "200")
+
+	fs, flags := newFlagSet()
+	err := fs.Parse([]string{"--profile", "hard", "--player-health", "120", "--seed", "42", "--bee", "queen.health=150", "--bee", "drone.amount=3", "--item", "smoke.quantity=4"})
+	if err != nil {
+		t.Fatalf("Parse() returned error: %v", err)
+	}
+
+	cfg, err := flags.Load()
+	if err != nil {
+		t.Fatalf("Load() returned error: %v", err)
+	}
+
+	if cfg.Profile != "hard" || cfg.PlayerHealth != 120 || cfg.RandomSeed != 42 {
+		t.Errorf("Expected flags to set profile, health and seed, got %+v", cfg)
+	}
+	if cfg.Species[0].Health != 150 || cfg.Species[2].Amount != 3 {
+		t.Errorf("Expected --bee flags to override species, got %+v", cfg.Species)
+	}
+	if cfg.Items[0].Quantity != 4 {
+		t.Errorf("Expected --item flag to override smoke quantity, got %+v", cfg.Items[0])
+	}
+}
+
+func TestFlagErrors(t *testing.T) {
+	tests := []struct {
+		args    []string
+		wantErr string
+	}{
+		{[]string{"--player-health", "lots"}, `"lots" is not a whole number`},
+		{[]string{"--bee", "queen"}, "expected species.key=value"},
+		{[]string{"--bee", "queen.wings=4"}, `unknown bee setting "WINGS"`},
+		{[]string{"--bee", "queen.health=tough"}, `"tough" is not a whole number`},
+		{[]string{"--item", "smoke.colour=grey"}, `unknown item setting "COLOUR"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(strings.Join(tt.args, " "), func(t *testing.T) {
+			fs, _ := newFlagSet()
+			err := fs.Parse(tt.args)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Expected error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+
+	// Unknown species can only be spotted once the config is loaded
+	fs, flags := newFlagSet()
+	if err := fs.Parse([]string{"--bee", "wasp.health=10"}); err != nil {
+		t.Fatalf("Parse() returned error: %v", err)
+	}
+	if _, err := flags.Load(); err == nil || !strings.Contains(err.Error(), `unknown bee species "wasp"`) {
+		t.Errorf("Expected unknown species error, got %v", err)
+	}
+}
@@ -0,0 +1,133 @@
+package config
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+const DefaultProfile = "normal"
+
+// profiles adjust the default config for each difficulty
+var profiles = map[string]func(c *Config){
+	"easy": func(c *Config) {
+		c.PlayerHealth = 150
+		c.PlayerMissChance = 0.05
+		c.HealUses = 5
+		for i := range c.Species {
+			c.Species[i].MissChance = 0.3
+			c.Species[i].SpawnInterval *= 2
+		}
+	},
+	"normal": func(c *Config) {},
+	"hard": func(c *Config) {
+		c.PlayerHealth = 80
+		c.PlayerMissChance = 0.15
+		c.SwarmSize = 2
+		for i := range c.Species {
+			c.Species[i].MissChance = 0.15
+			c.Species[i].CritChance = 0.1
+		}
+	},
+	"nightmare": func(c *Config) {
+		c.PlayerHealth = 60
+		c.PlayerMissChance = 0.2
+		c.AimedMissPenalty = 0.25
+		c.HealUses = 1
+		c.SwarmMode = SwarmProportional
+		for i := range c.Species {
+			c.Species[i].MissChance = 0.1
+			c.Species[i].CritChance = 0.15
+			c.Species[i].Amount += c.Species[i].Amount / 2
+			// Spawn twice as often, but a bee that spawns still needs an interval
+			if c.Species[i].SpawnInterval > 0 {
+				c.Species[i].SpawnInterval = max(c.Species[i].SpawnInterval/2, 1)
+			}
+		}
+		c.MaxHiveSize = 60
+	},
+}
+
+// Profiles lists the names of the difficulty profiles
+func Profiles() []string {
+	names := make([]string, 0, len(profiles))
+	for name := range profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Default returns the config for the normal difficulty
+func Default() *Config {
+	return &Config{
+		Profile:              DefaultProfile,
+		PlayerHealth:         100,
+		LogSize:              10,
+		AutoRunSpeed:         1,
+		PlayerMissChance:     0.1,
+		PlayerCritChance:     0,
+		PlayerCritMultiplier: 2,
+		AimedMissPenalty:     0.15,
+		DefendReduction:      0.5,
+		DodgeBonus:           0.3,
+		HealAmount:           25,
+		HealUses:             3,
+		CollapseRule:         CollapseFirst,
+		MaxHiveSize:          40,
+		SwarmMode:            SwarmFixed,
+		SwarmSize:            1,
+		SwarmRatio:           0.1,
+		Poison:               StatusEffect{Turns: 3, MaxStacks: 3, Damage: 1},
+		Stun:                 StatusEffect{Turns: 1, MaxStacks: 1},
+		Enrage:               StatusEffect{Turns: 3, MaxStacks: 2, Bonus: 0.5},
+		Species: []BeeSpecies{
+			{Name: "Queen", Amount: 1, Health: 100, AttackDamage: 10, HitDamage: 10, MissChance: 0.2, CritMultiplier: 2, TargetWeight: 0.5, AttackWeight: 0.5, StunChance: 0.25, Leader: true, Spawns: "Drone", SpawnInterval: 4, Glyph: "👑"},
+			{Name: "Worker", Amount: 5, Health: 75, AttackDamage: 5, HitDamage: 25, MissChance: 0.2, CritMultiplier: 2, PoisonChance: 0.2, Role: RoleHealer, HealAmount: 10, Glyph: "🐝"},
+			{Name: "Drone", Amount: 25, Health: 60, AttackDamage: 1, HitDamage: 30, MissChance: 0.2, CritMultiplier: 2, Glyph: "💤"},
+		},
+		Loadouts: []Loadout{
+			{Name: "newspaper", Weapon: Weapon{Name: "rolled-up newspaper"}, Armor: Armor{Name: "t-shirt"}},
+			{Name: "rake", Weapon: Weapon{Name: "garden rake", Damage: 10, Accuracy: -0.1, Bonus: map[string]float64{"Queen": 1.5}}, Armor: Armor{Name: "denim jacket", Reduction: 0.1}},
+			{Name: "swatter", Weapon: Weapon{Name: "fly swatter", Accuracy: 0.05, Bonus: map[string]float64{"Drone": 1.5}}, Armor: Armor{Name: "beekeeper suit", Flat: 1}},
+		},
+		Classes: []Class{
+			{
+				Name: "Beekeeper", Description: "tougher, and can calm the hive with smoke", Health: 20,
+				Ability: Ability{Name: "calm", Effect: EffectPacify, Cooldown: 6, Turns: 2},
+			},
+			{
+				Name: "Exterminator", Description: "hits harder, and can fumigate a group of bees", Health: -10, Damage: 5,
+				Ability: Ability{Name: "fumigate", Effect: EffectAreaDamage, Cooldown: 5, Amount: 20, Targets: 4},
+			},
+			{
+				Name: "Scout", Description: "quick on their feet, and can slip every sting for a while", Accuracy: 0.05, Dodge: 0.15,
+				Ability: Ability{Name: "evade", Effect: EffectEvade, Cooldown: 5, Turns: 2},
+			},
+		},
+		Items: []Item{
+			{Name: "smoke", Effect: EffectPacify, Quantity: 1, Turns: 2},
+			{Name: "spray", Effect: EffectAreaDamage, Quantity: 2, Amount: 15, Targets: 5},
+			{Name: "antihistamine", Effect: EffectHeal, Quantity: 1, Amount: 15, Turns: 3, Resist: 0.5},
+		},
+	}
+}
+
+// ProfileConfig returns the default config adjusted for a difficulty profile.
+// An empty name is the normal difficulty.
+func ProfileConfig(name string) (*Config, error) {
+	if name == "" {
+		name = DefaultProfile
+	}
+
+	name = strings.ToLower(name)
+	adjust, ok := profiles[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown difficulty profile %q, choose from %s", name, strings.Join(Profiles(), ", "))
+	}
+
+	config := Default()
+	config.Profile = name
+	adjust(config)
+	return config, nil
+}
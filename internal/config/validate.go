@@ -0,0 +1,197 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+)
+
+// Validate checks the config makes sense for a game, reporting every problem
+// it finds rather than just the first
+func (c *Config) Validate() error {
+	var errs []error
+	check := func(ok bool, format string, args ...any) {
+		if !ok {
+			errs = append(errs, fmt.Errorf(format, args...))
+		}
+	}
+
+	check(c.PlayerHealth > 0, "player health must be positive, got %d", c.PlayerHealth)
+	check(c.LogSize > 0, "log size must be positive, got %d", c.LogSize)
+	check(c.AutoRunSpeed >= 0, "auto run speed can't be negative, got %d", c.AutoRunSpeed)
+	check(isChance(c.PlayerMissChance), "player miss chance must be between 0 and 1, got %v", c.PlayerMissChance)
+	check(isChance(c.PlayerCritChance), "player crit chance must be between 0 and 1, got %v", c.PlayerCritChance)
+	check(c.PlayerCritMultiplier >= 1, "player crit multiplier must be at least 1, got %v", c.PlayerCritMultiplier)
+	check(isChance(c.AimedMissPenalty), "aimed miss penalty must be between 0 and 1, got %v", c.AimedMissPenalty)
+	check(isChance(c.DefendReduction), "defend reduction must be between 0 and 1, got %v", c.DefendReduction)
+	check(isChance(c.DodgeBonus), "dodge bonus must be between 0 and 1, got %v", c.DodgeBonus)
+	check(c.HealAmount >= 0, "heal amount can't be negative, got %d", c.HealAmount)
+	check(c.HealUses >= 0, "heal uses can't be negative, got %d", c.HealUses)
+	switch c.CollapseRule {
+	case "", CollapseFirst, CollapseLast, CollapseNever:
+	default:
+		check(false, "unknown collapse rule %q, choose from %s, %s or %s", c.CollapseRule, CollapseFirst, CollapseLast, CollapseNever)
+	}
+
+	switch c.SwarmMode {
+	case "", SwarmFixed:
+		check(c.SwarmSize >= 0, "swarm size can't be negative, got %d", c.SwarmSize)
+	case SwarmProportional:
+		check(c.SwarmRatio > 0 && c.SwarmRatio <= 1, "swarm ratio must be above 0 and at most 1, got %v", c.SwarmRatio)
+	case SwarmPerType:
+	default:
+		check(false, "unknown swarm mode %q, choose from %s, %s or %s", c.SwarmMode, SwarmFixed, SwarmProportional, SwarmPerType)
+	}
+	effects := []struct {
+		name   string
+		effect StatusEffect
+	}{{"poison", c.Poison}, {"stun", c.Stun}, {"enrage", c.Enrage}}
+	for _, e := range effects {
+		check(e.effect.Turns >= 0, "%s turns can't be negative, got %d", e.name, e.effect.Turns)
+		check(e.effect.MaxStacks >= 0, "%s max stacks can't be negative, got %d", e.name, e.effect.MaxStacks)
+		check(e.effect.Damage >= 0, "%s damage can't be negative, got %d", e.name, e.effect.Damage)
+		check(e.effect.Bonus >= 0, "%s bonus can't be negative, got %v", e.name, e.effect.Bonus)
+	}
+	check(c.MaxHiveSize >= 0, "max hive size can't be negative, got %d", c.MaxHiveSize)
+
+	seen := map[string]bool{}
+	bees := 0
+	for i, s := range c.Species {
+		name := s.Name
+		if name == "" {
+			name = fmt.Sprintf("#%d", i+1)
+			check(false, "bee species %s is missing a name", name)
+		}
+		check(!seen[strings.ToLower(s.Name)], "bee species %s is defined more than once", name)
+		seen[strings.ToLower(s.Name)] = true
+
+		check(s.Amount >= 0, "%s bee amount can't be negative, got %d", name, s.Amount)
+		check(s.Health > 0, "%s bee health must be positive, got %d", name, s.Health)
+		check(s.AttackDamage >= 0, "%s bee attack damage can't be negative, got %d", name, s.AttackDamage)
+		check(s.AttackDamageMax == 0 || s.AttackDamageMax >= s.AttackDamage, "%s bee attack damage max %d is below its attack damage %d", name, s.AttackDamageMax, s.AttackDamage)
+		check(s.HitDamage > 0, "%s bee hit damage must be positive, got %d", name, s.HitDamage)
+		check(s.HitDamageMax == 0 || s.HitDamageMax >= s.HitDamage, "%s bee hit damage max %d is below its hit damage %d", name, s.HitDamageMax, s.HitDamage)
+		check(isChance(s.MissChance), "%s bee miss chance must be between 0 and 1, got %v", name, s.MissChance)
+		check(isChance(s.CritChance), "%s bee crit chance must be between 0 and 1, got %v", name, s.CritChance)
+		check(s.TargetWeight >= 0, "%s bee target weight can't be negative, got %v", name, s.TargetWeight)
+		check(s.AttackWeight >= 0, "%s bee attack weight can't be negative, got %v", name, s.AttackWeight)
+		check(isChance(s.PoisonChance), "%s bee poison chance must be between 0 and 1, got %v", name, s.PoisonChance)
+		check(isChance(s.StunChance), "%s bee stun chance must be between 0 and 1, got %v", name, s.StunChance)
+		check(s.CritChance == 0 || s.CritMultiplier >= 1, "%s bee crit multiplier must be at least 1, got %v", name, s.CritMultiplier)
+		switch s.Role {
+		case "", RoleStinger, RoleGuard:
+		case RoleHealer:
+			check(s.HealAmount > 0, "%s bee is a healer but has no heal amount", name)
+		default:
+			check(false, "%s bee has unknown role %q, choose from %s, %s or %s", name, s.Role, RoleStinger, RoleHealer, RoleGuard)
+		}
+		check(s.HealAmount >= 0, "%s bee heal amount can't be negative, got %d", name, s.HealAmount)
+		check(isChance(s.GuardChance), "%s bee guard chance must be between 0 and 1, got %v", name, s.GuardChance)
+		check(s.SpawnInterval >= 0, "%s bee spawn interval can't be negative, got %d", name, s.SpawnInterval)
+		check(s.Spawns == "" || s.SpawnInterval > 0, "%s bee spawns %s but has no spawn interval", name, s.Spawns)
+		check(s.Spawns == "" || c.hasSpecies(s.Spawns), "%s bee spawns unknown species %q", name, s.Spawns)
+
+		bees += max(s.Amount, 0)
+	}
+
+	check(bees > 0, "the hive must have at least one bee")
+
+	seen = map[string]bool{}
+	for i, l := range c.Loadouts {
+		name := l.Name
+		if name == "" {
+			name = fmt.Sprintf("#%d", i+1)
+			check(false, "loadout %s is missing a name", name)
+		}
+		check(!seen[strings.ToLower(l.Name)], "loadout %s is defined more than once", name)
+		seen[strings.ToLower(l.Name)] = true
+
+		check(l.Weapon.Damage >= 0, "%s weapon damage can't be negative, got %d", name, l.Weapon.Damage)
+		check(l.Weapon.Accuracy >= -1 && l.Weapon.Accuracy <= 1, "%s weapon accuracy must be between -1 and 1, got %v", name, l.Weapon.Accuracy)
+		// Bonuses against species that aren't in the hive are allowed, so
+		// loadouts still work with custom bees
+		for _, species := range slices.Sorted(maps.Keys(l.Weapon.Bonus)) {
+			check(l.Weapon.Bonus[species] >= 0, "%s weapon bonus against %s can't be negative, got %v", name, species, l.Weapon.Bonus[species])
+		}
+		check(l.Armor.Flat >= 0, "%s armor can't have negative flat reduction, got %d", name, l.Armor.Flat)
+		check(isChance(l.Armor.Reduction), "%s armor reduction must be between 0 and 1, got %v", name, l.Armor.Reduction)
+	}
+	check(c.Loadout == "" || seen[strings.ToLower(c.Loadout)], "unknown loadout %q", c.Loadout)
+
+	seen = map[string]bool{}
+	for i, class := range c.Classes {
+		name := class.Name
+		if name == "" {
+			name = fmt.Sprintf("#%d", i+1)
+			check(false, "class %s is missing a name", name)
+		}
+		check(!seen[strings.ToLower(class.Name)], "class %s is defined more than once", name)
+		seen[strings.ToLower(class.Name)] = true
+
+		check(c.PlayerHealth+class.Health > 0, "%s must start with positive health, got %d", name, c.PlayerHealth+class.Health)
+		check(class.Damage >= 0, "%s damage can't be negative, got %d", name, class.Damage)
+		check(class.Accuracy >= -1 && class.Accuracy <= 1, "%s accuracy must be between -1 and 1, got %v", name, class.Accuracy)
+		check(isChance(class.Dodge), "%s dodge must be between 0 and 1, got %v", name, class.Dodge)
+
+		// A class doesn't need an ability
+		ability := class.Ability
+		if ability.Name == "" && ability.Effect == "" {
+			continue
+		}
+		check(ability.Name != "" && !strings.ContainsAny(ability.Name, " \t"), "%s ability needs a name without spaces, got %q", name, ability.Name)
+		check(ability.Cooldown >= 0, "%s ability cooldown can't be negative, got %d", name, ability.Cooldown)
+		checkEffect(check, name+" ability", ability.Effect, ability.Amount, ability.Targets, ability.Turns, ability.Resist)
+	}
+	check(c.Class == "" || seen[strings.ToLower(c.Class)], "unknown class %q", c.Class)
+
+	seen = map[string]bool{}
+	for i, item := range c.Items {
+		name := item.Name
+		if name == "" {
+			name = fmt.Sprintf("#%d", i+1)
+			check(false, "item %s is missing a name", name)
+		}
+		check(!strings.ContainsAny(item.Name, " \t"), "item %s can't have spaces in its name", name)
+		check(!seen[strings.ToLower(item.Name)], "item %s is defined more than once", name)
+		seen[strings.ToLower(item.Name)] = true
+
+		check(item.Quantity >= 0, "%s quantity can't be negative, got %d", name, item.Quantity)
+		checkEffect(check, name, item.Effect, item.Amount, item.Targets, item.Turns, item.Resist)
+	}
+
+	return errors.Join(errs...)
+}
+
+// checkEffect validates the effect of an item or ability
+func checkEffect(check func(ok bool, format string, args ...any), name, effect string, amount, targets, turns int, resist float64) {
+	check(amount >= 0, "%s amount can't be negative, got %d", name, amount)
+	check(turns >= 0, "%s turns can't be negative, got %d", name, turns)
+	check(isChance(resist), "%s resist must be between 0 and 1, got %v", name, resist)
+
+	switch effect {
+	case EffectPacify:
+		check(turns > 0, "%s must pacify the bees for at least one turn", name)
+	case EffectAreaDamage:
+		check(targets > 0, "%s must target at least one bee, got %d", name, targets)
+	case EffectHeal:
+	case EffectEvade:
+		check(turns > 0, "%s must evade for at least one turn", name)
+	default:
+		check(false, "%s has unknown effect %q, choose from %s, %s, %s or %s", name, effect, EffectPacify, EffectAreaDamage, EffectHeal, EffectEvade)
+	}
+}
+
+func (c *Config) hasSpecies(name string) bool {
+	for _, s := range c.Species {
+		if strings.EqualFold(s.Name, name) {
+			return true
+		}
+	}
+	return false
+}
+
+func isChance(f float64) bool {
+	return f >= 0 && f <= 1
+}
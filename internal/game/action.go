@@ -0,0 +1,224 @@
+package game
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/lewwolfe/beesinthetrap/internal/config"
+)
+
+type ActionType int
+
+// Actions the player can take on their turn
+const (
+	ActionHit ActionType = iota
+	ActionDefend
+	ActionDodge
+	ActionHeal
+	ActionFlee
+	ActionUse
+	ActionSpecial
+)
+
+// Commands without a target, by what the player types
+var simpleActions = map[string]ActionType{
+	"defend":  ActionDefend,
+	"dodge":   ActionDodge,
+	"heal":    ActionHeal,
+	"flee":    ActionFlee,
+	"special": ActionSpecial,
+}
+
+// Action is a parsed player command. For a hit, Target is empty for a random
+// hit, otherwise it is a bee type name or a bee number from the hive listing.
+// For use, Target is the name of the item.
+type Action struct {
+	Type   ActionType
+	Target string
+}
+
+// ParseAction turns player input such as "hit" or "hit queen" into an Action
+func ParseAction(input string) (Action, error) {
+	fields := strings.Fields(strings.ToLower(input))
+	if len(fields) == 0 {
+		return Action{}, errors.New("no command given")
+	}
+
+	switch fields[0] {
+	case "hit":
+		if len(fields) > 2 {
+			return Action{}, errors.New("hit takes at most one target")
+		}
+		action := Action{Type: ActionHit}
+		if len(fields) == 2 {
+			action.Target = fields[1]
+		}
+		return action, nil
+	case "use":
+		if len(fields) != 2 {
+			return Action{}, errors.New("use takes one item, e.g. 'use smoke'")
+		}
+		return Action{Type: ActionUse, Target: fields[1]}, nil
+	}
+
+	if actionType, ok := simpleActions[fields[0]]; ok {
+		if len(fields) > 1 {
+			return Action{}, fmt.Errorf("%s doesn't take a target", fields[0])
+		}
+		return Action{Type: actionType}, nil
+	}
+
+	return Action{}, fmt.Errorf("unknown command %q", fields[0])
+}
+
+// validateAction checks the action can be taken right now
+func (ge *GameEngine) validateAction(action Action) error {
+	switch action.Type {
+	case ActionHit:
+		_, err := ge.targetCandidates(action.Target)
+		return err
+	case ActionHeal:
+		if ge.player.healsLeft <= 0 {
+			return errors.New("you have no heals left")
+		}
+	case ActionUse:
+		_, err := ge.usableItem(action.Target)
+		return err
+	case ActionSpecial:
+		_, err := ge.readyAbility()
+		return err
+	}
+	return nil
+}
+
+// defend braces the player, reducing the damage of stings until their next turn
+func (ge *GameEngine) defend() {
+	ge.player.defending = true
+	ge.PlayerDefends++
+	ge.emit(Event{Type: PlayerDefended})
+}
+
+// dodge makes the bees more likely to miss until the player's next turn
+func (ge *GameEngine) dodge() {
+	ge.player.dodging = true
+	ge.PlayerDodges++
+	ge.emit(Event{Type: PlayerDodged})
+}
+
+func (ge *GameEngine) heal() error {
+	if ge.player.healsLeft <= 0 {
+		return errors.New("you have no heals left")
+	}
+
+	ge.player.healsLeft--
+	healed := ge.player.Heal(ge.Config.HealAmount)
+	ge.PlayerHeals++
+	ge.emit(Event{Type: PlayerHealed, Healed: healed, HP: ge.player.hp})
+	return nil
+}
+
+// flee ends the game as a retreat
+func (ge *GameEngine) flee() {
+	ge.fled = true
+	ge.emit(Event{Type: PlayerRetreated})
+}
+
+// usableItem looks up an item by name, checking the player has one left
+func (ge *GameEngine) usableItem(name string) (config.Item, error) {
+	for _, item := range ge.Config.Items {
+		if strings.EqualFold(item.Name, name) {
+			if ge.player.items[item.Name] <= 0 {
+				return item, fmt.Errorf("you have no %s left", item.Name)
+			}
+			return item, nil
+		}
+	}
+	return config.Item{}, fmt.Errorf("unknown item %q", name)
+}
+
+// useItem consumes one of the player's items and applies its effect
+func (ge *GameEngine) useItem(name string) error {
+	item, err := ge.usableItem(name)
+	if err != nil {
+		return err
+	}
+
+	ge.player.items[item.Name]--
+	ge.ItemsUsed++
+	ge.applyEffect(Event{Type: ItemUsed, Item: item.Name}, item)
+	return nil
+}
+
+// readyAbility returns the player's special ability, checking it has
+// recharged since it was last used
+func (ge *GameEngine) readyAbility() (config.Ability, error) {
+	ability := ge.player.class.Ability
+	switch {
+	case ability.Effect == "":
+		return ability, errors.New("you have no special ability")
+	case ge.player.cooldown == 1:
+		return ability, fmt.Errorf("%s is ready next turn", ability.Name)
+	case ge.player.cooldown > 1:
+		return ability, fmt.Errorf("%s is ready in %d turns", ability.Name, ge.player.cooldown)
+	}
+	return ability, nil
+}
+
+// special uses the player's class ability, which then has to recharge
+func (ge *GameEngine) special() error {
+	ability, err := ge.readyAbility()
+	if err != nil {
+		return err
+	}
+
+	ge.player.cooldown = ability.Cooldown
+	ge.AbilitiesUsed++
+	// Abilities have the same effects as items
+	ge.applyEffect(Event{Type: AbilityUsed, Ability: ability.Name}, config.Item{
+		Effect:  ability.Effect,
+		Amount:  ability.Amount,
+		Targets: ability.Targets,
+		Turns:   ability.Turns,
+		Resist:  ability.Resist,
+	})
+	return nil
+}
+
+// applyEffect carries out the effect of an item or ability, announcing it
+// with the used event
+func (ge *GameEngine) applyEffect(used Event, item config.Item) {
+	switch item.Effect {
+	case config.EffectPacify:
+		ge.pacifiedTurns = max(ge.pacifiedTurns, item.Turns)
+		used.Turns = item.Turns
+		ge.emit(used)
+	case config.EffectAreaDamage:
+		ge.emit(used)
+		ge.damageBees(item.Amount, item.Targets)
+	case config.EffectHeal:
+		ge.player.resist = item.Resist
+		ge.player.resistTurns = item.Turns
+		used.Turns = item.Turns
+		ge.emit(used)
+		healed := ge.player.Heal(item.Amount)
+		ge.emit(Event{Type: PlayerHealed, Healed: healed, HP: ge.player.hp})
+	case config.EffectEvade:
+		ge.player.evadeTurns = max(ge.player.evadeTurns, item.Turns)
+		used.Turns = item.Turns
+		ge.emit(used)
+	}
+}
+
+// damageBees deals damage to up to targets different bees picked at random
+func (ge *GameEngine) damageBees(damage, targets int) {
+	for _, bee := range ge.pickBees(targets, byTargetWeight) {
+		// A leader dying takes the rest of the targets with it
+		if len(ge.hive) == 0 {
+			return
+		}
+		bee.Hit(damage)
+		ge.emit(Event{Type: BeeHit, BeeType: bee.beeType, BeeID: bee.id, Damage: damage, HP: bee.hp})
+		ge.checkBeeDead(bee)
+	}
+}
@@ -0,0 +1,135 @@
+package game
+
+import (
+	"testing"
+
+	"github.com/lewwolfe/beesinthetrap/internal/config"
+)
+
+// TestParseAction tests that player input is turned into the right action
+func TestParseAction(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected Action
+		wantErr  bool
+	}{
+		{"hit", Action{Type: ActionHit}, false},
+		{"  HIT  ", Action{Type: ActionHit}, false},
+		{"hit queen", Action{Type: ActionHit, Target: "queen"}, false},
+		{"hit 3", Action{Type: ActionHit, Target: "3"}, false},
+		{"defend", Action{Type: ActionDefend}, false},
+		{"dodge", Action{Type: ActionDodge}, false},
+		{"Heal", Action{Type: ActionHeal}, false},
+		{"flee", Action{Type: ActionFlee}, false},
+		{"use Smoke", Action{Type: ActionUse, Target: "smoke"}, false},
+		{"special", Action{Type: ActionSpecial}, false},
+		{"special now", Action{}, true},
+		{"use", Action{}, true},
+		{"use bug spray", Action{}, true},
+		{"heal 5", Action{}, true},
+		{"hit queen now", Action{}, true},
+		{"kick", Action{}, true},
+		{"", Action{}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			action, err := ParseAction(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseAction(%q) error = %v, wantErr %v", tt.input, err, tt.wantErr)
+			}
+			if action != tt.expected {
+				t.Errorf("ParseAction(%q) = %+v, want %+v", tt.input, action, tt.expected)
+			}
+		})
+	}
+}
+
+// TestClassAbilities tests class stats and using and recharging a special
+func TestClassAbilities(t *testing.T) {
+	cfg := &config.Config{
+		PlayerHealth: 100,
+		RandomSeed:   12345,
+		Species: []config.BeeSpecies{
+			{Name: "Worker", Amount: 3, Health: 100, AttackDamage: 10, HitDamage: 10},
+		},
+		Classes: []config.Class{
+			{Name: "Brute", Health: 20, Damage: 5, Ability: config.Ability{Name: "fumigate", Effect: config.EffectAreaDamage, Cooldown: 2, Amount: 20, Targets: 3}},
+			{Name: "Ghost", Dodge: 0.5, Ability: config.Ability{Name: "vanish", Effect: config.EffectEvade, Cooldown: 3, Turns: 1}},
+			{Name: "Plain"},
+		},
+	}
+
+	ge := NewGame(cfg)
+
+	// The first class is used by default, with its extra health and damage
+	if ge.player.class.Name != "Brute" || ge.player.hp != 120 || ge.player.maxHP != 120 {
+		t.Errorf("Expected a Brute on 120 HP, got %s on %d/%d", ge.player.class.Name, ge.player.hp, ge.player.maxHP)
+	}
+	if damage := ge.player.HitDamage(10, WorkerBee); damage != 15 {
+		t.Errorf("Expected the Brute to hit for 15, got %d", damage)
+	}
+
+	// Auto mode uses the ability as soon as it's ready
+	if action := ge.AutoAction(); action.Type != ActionSpecial {
+		t.Errorf("Expected auto mode to use the special, got %+v", action)
+	}
+	if err := ge.TakeAction(Action{Type: ActionSpecial}); err != nil {
+		t.Fatalf("Expected the special to be ready, got %v", err)
+	}
+	for _, bee := range ge.hive {
+		if bee.hp != 80 {
+			t.Errorf("Expected fumigate to hit every worker for 20, got %d HP", bee.hp)
+		}
+	}
+
+	// Then it has to recharge
+	if err := ge.TakeAction(Action{Type: ActionSpecial}); err == nil {
+		t.Errorf("Expected the special to need recharging")
+	}
+	if action := ge.AutoAction(); action.Type != ActionHit {
+		t.Errorf("Expected auto mode to hit while recharging, got %+v", action)
+	}
+	ge.endPlayerTurn()
+	ge.endPlayerTurn()
+	if _, err := ge.readyAbility(); err != nil {
+		t.Errorf("Expected the special to recharge after 2 turns, got %v", err)
+	}
+
+	// A Ghost dodges a bit, and evading dodges everything
+	if err := ge.SetClass("ghost"); err != nil {
+		t.Fatalf("Expected class to be found, got %v", err)
+	}
+	if ge.player.hp != 100 || ge.player.cooldown != 0 {
+		t.Errorf("Expected a fresh Ghost on 100 HP, got %d HP and cooldown %d", ge.player.hp, ge.player.cooldown)
+	}
+	if bonus := ge.player.missBonus(0.3); bonus != 0.5 {
+		t.Errorf("Expected the Ghost's dodge to add 0.5 miss chance, got %v", bonus)
+	}
+	ge.TakeAction(Action{Type: ActionSpecial})
+	if bonus := ge.player.missBonus(0.3); bonus != 1 {
+		t.Errorf("Expected evading to always miss, got %v", bonus)
+	}
+	ge.player.endBeeTurn()
+	if bonus := ge.player.missBonus(0.3); bonus != 0.5 {
+		t.Errorf("Expected evade to wear off after a bee turn, got %v", bonus)
+	}
+
+	// Not every class has an ability
+	ge.SetClass("plain")
+	if _, err := ge.readyAbility(); err == nil {
+		t.Errorf("Expected no special ability for a plain class")
+	}
+	if err := ge.SetClass("wizard"); err == nil {
+		t.Errorf("Expected an error for an unknown class")
+	}
+	events := ge.Events()
+
+	counts := map[EventType]int{}
+	for _, e := range events {
+		counts[e.Type]++
+	}
+	if counts[AbilityUsed] != 2 || counts[AbilityReady] != 1 {
+		t.Errorf("Expected 2 abilities used and 1 ready, got %v", counts)
+	}
+}
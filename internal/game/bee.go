@@ -1,49 +1,140 @@
 package game
 
-import "math/rand"
+import (
+	"github.com/lewwolfe/beesinthetrap/internal/config"
+)
 
 type Bee struct {
-	beeType      BeeType
-	hp           int
-	attackDamage int
-	hitDamage    int
-	missChance   float64
+	id              int
+	beeType         BeeType
+	hp              int
+	maxHP           int
+	attackDamage    int
+	attackDamageMax int
+	hitDamage       int
+	hitDamageMax    int
+	missChance      float64
+	critChance      float64
+	critMultiplier  float64
+	targetWeight    float64
+	attackWeight    float64
+	poisonChance    float64
+	stunChance      float64
+	statuses        statuses
+	leader          bool
+	role            string
+	healAmount      int
+	guardChance     float64
+	spawns          BeeType
+	spawnInterval   int
 }
 
-type BeeType int
+// BeeType is the name of the bee's species from the config
+type BeeType string
 
-// Enum(ish) representation of bee types
+// Names of the species in the default hive
 const (
-	QueenBee BeeType = iota
-	WorkerBee
-	DroneBee
+	QueenBee  BeeType = "Queen"
+	WorkerBee BeeType = "Worker"
+	DroneBee  BeeType = "Drone"
 )
 
 func (bt BeeType) String() string {
-	return [...]string{"Queen", "Worker", "Drone"}[bt]
+	return string(bt)
 }
 
-func (b *Bee) Attack(rng *rand.Rand) int {
-	if rng.Float64() > b.missChance {
-		return b.attackDamage
+func newBee(species config.BeeSpecies) *Bee {
+	return &Bee{
+		beeType:         BeeType(species.Name),
+		hp:              species.Health,
+		maxHP:           species.Health,
+		attackDamage:    species.AttackDamage,
+		attackDamageMax: species.AttackDamageMax,
+		hitDamage:       species.HitDamage,
+		hitDamageMax:    species.HitDamageMax,
+		missChance:      species.MissChance,
+		critChance:      species.CritChance,
+		critMultiplier:  species.CritMultiplier,
+		targetWeight:    weightOrDefault(species.TargetWeight),
+		attackWeight:    weightOrDefault(species.AttackWeight),
+		poisonChance:    species.PoisonChance,
+		stunChance:      species.StunChance,
+		leader:          species.Leader,
+		role:            species.Role,
+		healAmount:      species.HealAmount,
+		guardChance:     species.GuardChance,
+		spawns:          BeeType(species.Spawns),
+		spawnInterval:   species.SpawnInterval,
 	}
-	return 0
+}
+
+// weightOrDefault gives unset selection weights the default of 1
+func weightOrDefault(weight float64) float64 {
+	if weight <= 0 {
+		return 1
+	}
+	return weight
+}
 
+// Weights for picking which bees get hit and which bees attack
+func byTargetWeight(b *Bee) float64 { return b.targetWeight }
+func byAttackWeight(b *Bee) float64 { return b.attackWeight }
+
+// Attack rolls the damage of a sting, returning 0 on a miss and whether the
+// sting was critical
+func (b *Bee) Attack(rng RNG) (int, bool) {
+	return b.DodgedAttack(rng, 0)
 }
 
-func (b *Bee) Hit() int {
-	b.hp -= b.hitDamage
-	return b.hitDamage
+// DodgedAttack is a sting at a dodging player, which is more likely to miss
+func (b *Bee) DodgedAttack(rng RNG, missBonus float64) (int, bool) {
+	if rng.Float64() > b.missChance+missBonus {
+		damage := rollDamage(rng, b.attackDamage, b.attackDamageMax)
+		return rollCrit(rng, damage, b.critChance, b.critMultiplier)
+	}
+	return 0, false
+}
+
+// RollHitDamage rolls how much damage a hit from the player does to this bee
+func (b *Bee) RollHitDamage(rng RNG) int {
+	return rollDamage(rng, b.hitDamage, b.hitDamageMax)
+}
+
+func (b *Bee) Hit(damage int) int {
+	b.hp -= damage
+	return damage
+}
+
+// Heal restores up to amount HP without going over the bee's max, and returns
+// how much was actually restored
+func (b *Bee) Heal(amount int) int {
+	healed := max(min(amount, b.maxHP-b.hp), 0)
+	b.hp += healed
+	return healed
+}
+
+// HasStatus reports whether the bee is under a status effect
+func (b *Bee) HasStatus(kind StatusKind) bool {
+	return b.statuses.stacks(kind) > 0
 }
 
 func (b *Bee) IsDead() bool {
 	return b.hp <= 0
 }
 
+func (b *Bee) GetID() int {
+	return b.id
+}
+
 func (b *Bee) GetBeeType() BeeType {
 	return b.beeType
 }
 
+// IsLeader reports whether the hive collapses when this bee dies
+func (b *Bee) IsLeader() bool {
+	return b.leader
+}
+
 func (b *Bee) GetHP() int {
 	return b.hp
 }
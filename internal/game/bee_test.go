@@ -18,7 +18,7 @@ func TestBeeAttack(t *testing.T) {
 	rng := rand.New(source)
 
 	// Test always hit
-	damage := alwaysHitBee.Attack(rng)
+	damage, _ := alwaysHitBee.Attack(rng)
 	if damage != 5 {
 		t.Errorf("Expected damage 5 when bee always hits, got %d", damage)
 	}
@@ -31,7 +31,7 @@ func TestBeeAttack(t *testing.T) {
 		missChance:   1,
 	}
 
-	damage = alwaysMissBee.Attack(rng)
+	damage, _ = alwaysMissBee.Attack(rng)
 	if damage != 0 {
 		t.Errorf("Expected damage 0 when bee always misses, got %d", damage)
 	}
@@ -45,7 +45,8 @@ func TestBeeHit(t *testing.T) {
 		hitDamage: 3,
 	}
 
-	damage := bee.Hit()
+	rng := rand.New(rand.NewSource(42))
+	damage := bee.Hit(bee.RollHitDamage(rng))
 	if damage != 3 {
 		t.Errorf("Expected hit damage 3, got %d", damage)
 	}
@@ -55,6 +56,54 @@ func TestBeeHit(t *testing.T) {
 	}
 }
 
+// TestBeeDamageRange tests that damage ranges stay within their bounds
+func TestBeeDamageRange(t *testing.T) {
+	bee := &Bee{
+		beeType:         WorkerBee,
+		hp:              10,
+		attackDamage:    2,
+		attackDamageMax: 6,
+		hitDamage:       10,
+		hitDamageMax:    20,
+	}
+
+	rng := rand.New(rand.NewSource(42))
+	seen := map[int]bool{}
+	for i := 0; i < 200; i++ {
+		damage, _ := bee.Attack(rng)
+		if damage < 2 || damage > 6 {
+			t.Fatalf("Expected sting damage between 2 and 6, got %d", damage)
+		}
+		seen[damage] = true
+
+		hitDamage := bee.RollHitDamage(rng)
+		if hitDamage < 10 || hitDamage > 20 {
+			t.Fatalf("Expected hit damage between 10 and 20, got %d", hitDamage)
+		}
+	}
+
+	if len(seen) != 5 {
+		t.Errorf("Expected every sting damage from 2 to 6 to be rolled, got %v", seen)
+	}
+}
+
+// TestBeeCritical tests that critical stings multiply the damage
+func TestBeeCritical(t *testing.T) {
+	bee := &Bee{
+		beeType:        QueenBee,
+		hp:             10,
+		attackDamage:   5,
+		critChance:     1,
+		critMultiplier: 2.5,
+	}
+
+	rng := rand.New(rand.NewSource(42))
+	damage, crit := bee.Attack(rng)
+	if !crit || damage != 13 {
+		t.Errorf("Expected critical sting for 13 damage, got %d (critical %v)", damage, crit)
+	}
+}
+
 // TestBeeIsDead tests that a bee correctly reports when it's dead
 func TestBeeIsDead(t *testing.T) {
 	aliveBee := &Bee{
@@ -0,0 +1,78 @@
+package game
+
+import (
+	"github.com/lewwolfe/beesinthetrap/internal/config"
+)
+
+// Behaviour is how a role of bee acts in the game. Species pick a role in the
+// config, and new roles only need adding to behaviours.
+type Behaviour interface {
+	// TakeTurn plays the bee's turn when it is picked on the bee turn
+	TakeTurn(ge *GameEngine, bee *Bee)
+	// Intercepts reports whether the bee steps in to take a hit aimed at target
+	Intercepts(ge *GameEngine, bee, target *Bee) bool
+}
+
+// behaviours by the role name from the config
+var behaviours = map[string]Behaviour{
+	"":                 stinger{},
+	config.RoleStinger: stinger{},
+	config.RoleHealer:  healer{},
+	config.RoleGuard:   guard{},
+}
+
+// behaviourOf returns the behaviour for the bee's role, stinging if the role
+// isn't known
+func behaviourOf(bee *Bee) Behaviour {
+	if b, ok := behaviours[bee.role]; ok {
+		return b
+	}
+	return stinger{}
+}
+
+// stinger just stings the player
+type stinger struct{}
+
+func (stinger) TakeTurn(ge *GameEngine, bee *Bee) {
+	ge.sting(bee)
+}
+
+func (stinger) Intercepts(ge *GameEngine, bee, target *Bee) bool {
+	return false
+}
+
+// healer patches up the most damaged bee, leaders first, and only stings when
+// no bee is hurt
+type healer struct {
+	stinger
+}
+
+func (healer) TakeTurn(ge *GameEngine, bee *Bee) {
+	var patient *Bee
+	for _, b := range ge.hive {
+		if b.hp >= b.maxHP {
+			continue
+		}
+		if patient == nil || (b.leader && !patient.leader) ||
+			(b.leader == patient.leader && b.maxHP-b.hp > patient.maxHP-patient.hp) {
+			patient = b
+		}
+	}
+
+	if patient == nil {
+		ge.sting(bee)
+		return
+	}
+
+	healed := patient.Heal(bee.healAmount)
+	ge.emit(Event{Type: BeeHealed, BeeType: bee.beeType, BeeID: bee.id, OtherType: patient.beeType, OtherID: patient.id, Healed: healed, HP: patient.hp})
+}
+
+// guard stings like any other bee, but may take a hit meant for a leader
+type guard struct {
+	stinger
+}
+
+func (guard) Intercepts(ge *GameEngine, bee, target *Bee) bool {
+	return bee != target && target.leader && ge.rng.Float64() < bee.guardChance
+}
@@ -0,0 +1,75 @@
+package game
+
+import (
+	"testing"
+
+	"github.com/lewwolfe/beesinthetrap/internal/config"
+)
+
+func behaviourConfig() *config.Config {
+	return &config.Config{
+		PlayerHealth: 100,
+		RandomSeed:   12345,
+		Species: []config.BeeSpecies{
+			{Name: "Queen", Amount: 1, Health: 100, AttackDamage: 10, HitDamage: 10, Leader: true},
+			{Name: "Guard", Amount: 1, Health: 80, AttackDamage: 5, HitDamage: 20, Role: config.RoleGuard, GuardChance: 1},
+			{Name: "Worker", Amount: 2, Health: 50, AttackDamage: 5, HitDamage: 10, Role: config.RoleHealer, HealAmount: 15},
+		},
+	}
+}
+
+// TestHealerPriority tests that healers patch up leaders first, then the most
+// damaged bee, and sting when nobody is hurt
+func TestHealerPriority(t *testing.T) {
+	ge := NewGame(behaviourConfig())
+
+	queen, _ := ge.GetBee(1)
+	worker, _ := ge.GetBee(3)
+	other, _ := ge.GetBee(4)
+
+	// Nobody is hurt, so the healer stings
+	behaviourOf(worker).TakeTurn(ge, worker)
+	if ge.player.hp != 95 {
+		t.Errorf("Expected healer to sting with no bees hurt, got player HP %d", ge.player.hp)
+	}
+
+	// The queen is healed before a more damaged worker
+	queen.hp = 90
+	other.hp = 10
+	behaviourOf(worker).TakeTurn(ge, worker)
+	if queen.hp != 100 || other.hp != 10 {
+		t.Errorf("Expected the queen to be healed first, got queen %d and worker %d", queen.hp, other.hp)
+	}
+
+	// Then the most damaged bee
+	worker.hp = 45
+	behaviourOf(worker).TakeTurn(ge, worker)
+	if other.hp != 25 || worker.hp != 45 {
+		t.Errorf("Expected the most damaged worker to be healed, got %d and %d", other.hp, worker.hp)
+	}
+	events := ge.Events()
+
+	healed := 0
+	for _, e := range events {
+		if e.Type == BeeHealed {
+			healed++
+		}
+	}
+	if healed != 2 {
+		t.Errorf("Expected 2 BeeHealed events, got %d", healed)
+	}
+}
+
+// TestGuardIntercepts tests that a guard takes an aimed hit at the queen
+func TestGuardIntercepts(t *testing.T) {
+	ge := NewGame(behaviourConfig())
+	if err := ge.TakeAction(Action{Type: ActionHit, Target: "queen"}); err != nil {
+		t.Fatalf("TakeAction() returned error: %v", err)
+	}
+
+	queen, _ := ge.GetBee(1)
+	guard, _ := ge.GetBee(2)
+	if queen.hp != 100 || guard.hp != 60 {
+		t.Errorf("Expected the guard to take the hit, got queen %d and guard %d", queen.hp, guard.hp)
+	}
+}
@@ -0,0 +1,96 @@
+package game
+
+type EventType int
+
+// Everything the engine reports back to its clients
+const (
+	PlayerPrompt EventType = iota
+	InvalidCommand
+	PlayerMissed
+	BeeHit
+	BeeKilled
+	HiveCollapsed
+	BeeStung
+	BeeMissed
+	GameOver
+	// The game never sends these three itself. They are for clients to
+	// report their own saving and loading the same way as everything else.
+	GameSaved
+	GameLoaded
+	CommandFailed
+	PlayerDefended
+	PlayerDodged
+	PlayerHealed
+	PlayerRetreated
+	ItemUsed
+	BeesPacified
+	BeeSpawned
+	BeeHealed
+	BeeGuarded
+	SwarmAttacked
+	StatusApplied
+	StatusDamage
+	StatusExpired
+	PlayerStunned
+	AbilityUsed
+	AbilityReady
+)
+
+func (et EventType) String() string {
+	return [...]string{
+		"PlayerPrompt",
+		"InvalidCommand",
+		"PlayerMissed",
+		"BeeHit",
+		"BeeKilled",
+		"HiveCollapsed",
+		"BeeStung",
+		"BeeMissed",
+		"GameOver",
+		"GameSaved",
+		"GameLoaded",
+		"CommandFailed",
+		"PlayerDefended",
+		"PlayerDodged",
+		"PlayerHealed",
+		"PlayerRetreated",
+		"ItemUsed",
+		"BeesPacified",
+		"BeeSpawned",
+		"BeeHealed",
+		"BeeGuarded",
+		"SwarmAttacked",
+		"StatusApplied",
+		"StatusDamage",
+		"StatusExpired",
+		"PlayerStunned",
+		"AbilityUsed",
+		"AbilityReady",
+	}[et]
+}
+
+// Event describes a single thing that happened in the game. Only the fields
+// relevant to the event type are set, and each field means the same thing
+// for every type that sets it. The public Event in pkg/hive lists them.
+type Event struct {
+	Type      EventType
+	BeeType   BeeType
+	BeeID     int
+	OtherType BeeType
+	OtherID   int
+	Damage    int
+	Blocked   int
+	Healed    int
+	HP        int
+	Critical  bool
+	Count     int
+	Status    StatusKind
+	Stacks    int
+	Turns     int
+	Item      string
+	Ability   string
+	Input     string
+	Path      string
+	State     GameState
+	Err       string
+}
@@ -2,8 +2,14 @@ package game
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"math"
 	"math/rand"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/lewwolfe/beesinthetrap/internal/config"
@@ -15,78 +21,109 @@ const (
 	Running GameState = iota
 	PlayerWin
 	PlayerLose
+	PlayerFled
 )
 
 type GameEngine struct {
 	Config        *config.Config
 	player        *Player
 	hive          []*Bee
+	nextBeeID     int
+	turn          int
 	playerTurn    bool
 	PlayerHits    int
 	BeeStings     int
+	PlayerDefends int
+	PlayerDodges  int
+	PlayerHeals   int
+	DamageBlocked int
+	ItemsUsed     int
+	AbilitiesUsed int
+	PoisonDamage  int
+	TurnsStunned  int
+	BeesEnraged   int
+	fled          bool
+	pacifiedTurns int
 	InputChan     chan string
-	OutputChan    chan string
+	EventChan     chan Event
 	GameStateChan chan GameState
-	rng           *rand.Rand
+	seed          int64
+	source        *countingSource
+	rng           RNG
+	replay        *Replay
+	events        []Event // queued until they are taken by Events, Step or Start
+	done          chan struct{}
+	mu            sync.Mutex // guards err, view and snapshot
+	err           error
+	view          *View
+	snapshot      *Snapshot // as of the published view, for Save
+	resumed       bool      // carrying on from a snapshot, so the player is already set up
+	customRNG     bool      // drawing from an RNG set with SetRNG, which can't be saved
 }
 
 func NewGame(cfg *config.Config) *GameEngine {
+	// Keep a copy of the config, as the game fills in its class and loadout
+	copied := *cfg
+	cfg = &copied
+
 	//Input a random seed for randomness, this allows for repetable games for testing
 	seed := cfg.RandomSeed
 	if seed == 0 {
 		seed = time.Now().Unix()
 	}
-	source := rand.NewSource(seed)
-	rng := rand.New(source)
+	source := newCountingSource(seed, 0)
 
 	ge := &GameEngine{
-		Config:        cfg,
-		player:        &Player{hp: cfg.PlayerHealth, missChance: cfg.PlayerMissChance},
+		Config: cfg,
+		player: &Player{
+			hp:             cfg.PlayerHealth,
+			maxHP:          cfg.PlayerHealth,
+			healsLeft:      cfg.HealUses,
+			missChance:     cfg.PlayerMissChance,
+			critChance:     cfg.PlayerCritChance,
+			critMultiplier: cfg.PlayerCritMultiplier,
+			items:          map[string]int{},
+		},
 		playerTurn:    true,
+		nextBeeID:     1,
 		PlayerHits:    0,
 		BeeStings:     0,
 		InputChan:     make(chan string),
-		OutputChan:    make(chan string),
+		EventChan:     make(chan Event),
 		GameStateChan: make(chan GameState, 1),
-		rng:           rng,
+		done:          make(chan struct{}),
+		seed:          seed,
+		source:        source,
+		rng:           rand.New(source),
 	}
 
-	// Spawn worker bees
-	for i := 0; i < cfg.WorkerBeeAmount; i++ {
-		ge.hive = append(ge.hive, &Bee{
-			beeType:      WorkerBee,
-			hp:           cfg.WorkerBeeHealth,
-			attackDamage: cfg.WorkerBeeAttackDamage,
-			hitDamage:    cfg.WorkerBeeHitDamage,
-			missChance:   cfg.BeeMissChance,
-		})
+	for _, item := range cfg.Items {
+		ge.player.items[item.Name] = item.Quantity
 	}
-
-	// Spawn drone bees
-	for i := 0; i < cfg.DroneBeeAmount; i++ {
-		ge.hive = append(ge.hive, &Bee{
-			beeType:      DroneBee,
-			hp:           cfg.DroneBeeHealth,
-			attackDamage: cfg.DroneBeeAttackDamage,
-			hitDamage:    cfg.DroneBeeHitDamage,
-			missChance:   cfg.BeeMissChance,
-		})
+	ge.player.loadout, _ = findLoadout(cfg, cfg.Loadout)
+	class, _ := findClass(cfg, cfg.Class)
+	ge.player.setClass(class, cfg.PlayerHealth)
+	cfg.Loadout, cfg.Class = ge.player.loadout.Name, class.Name
+
+	// Spawn the hive, species by species
+	for _, species := range cfg.Species {
+		for i := 0; i < species.Amount; i++ {
+			ge.spawnBee(newBee(species))
+		}
 	}
 
-	// Spawn Queen bee(s)
-	for i := 0; i < cfg.QueenBeeAmount; i++ {
-		ge.hive = append(ge.hive, &Bee{
-			beeType:      QueenBee,
-			hp:           cfg.QueenBeeHealth,
-			attackDamage: cfg.QueenBeeAttackDamage,
-			hitDamage:    cfg.QueenBeeHitDamage,
-			missChance:   cfg.BeeMissChance,
-		})
-	}
+	// Record everything from the starting hive onwards
+	ge.replay = &Replay{Start: ge.Snapshot()}
+	ge.publish()
 
 	return ge
 }
 
+// GetTurn returns how many bee turns have been played
+func (ge *GameEngine) GetTurn() int {
+	return ge.turn
+}
+
 func (ge *GameEngine) IsPlayerTurn() bool {
 	return ge.playerTurn
 }
@@ -99,112 +136,632 @@ func (ge *GameEngine) GetPlayer() *Player {
 	return ge.player
 }
 
+// GetBee looks up a bee in the hive by its ID
+func (ge *GameEngine) GetBee(id int) (*Bee, bool) {
+	for _, bee := range ge.hive {
+		if bee.id == id {
+			return bee, true
+		}
+	}
+	return nil, false
+}
+
 func (ge *GameEngine) ClearHive() {
 	ge.hive = []*Bee{}
 }
 
-// Start runs the game loop, handling turns and input
+// findSpecies looks up a bee species from the config by name, ignoring case
+func (ge *GameEngine) findSpecies(name string) (config.BeeSpecies, bool) {
+	for _, species := range ge.Config.Species {
+		if strings.EqualFold(species.Name, name) {
+			return species, true
+		}
+	}
+	return config.BeeSpecies{}, false
+}
+
+// findLoadout looks up a loadout from the config by name, ignoring case. An
+// empty name is the first loadout, or none at all if there aren't any.
+func findLoadout(cfg *config.Config, name string) (config.Loadout, bool) {
+	if name == "" && len(cfg.Loadouts) > 0 {
+		return cfg.Loadouts[0], true
+	}
+	for _, loadout := range cfg.Loadouts {
+		if strings.EqualFold(loadout.Name, name) {
+			return loadout, true
+		}
+	}
+	return config.Loadout{}, name == ""
+}
+
+// SetLoadout gives the player a different loadout from the config. It is
+// only meant for before the game starts, and restarts the replay from the
+// new loadout.
+func (ge *GameEngine) SetLoadout(name string) error {
+	if ge.resumed {
+		return errors.New("can't change the loadout of a resumed game")
+	}
+	loadout, ok := findLoadout(ge.Config, name)
+	if !ok {
+		return fmt.Errorf("unknown loadout %q", name)
+	}
+
+	ge.player.loadout = loadout
+	ge.Config.Loadout = loadout.Name
+	ge.replay = &Replay{Start: ge.Snapshot()}
+	ge.publish()
+	return nil
+}
+
+// findClass looks up a class from the config by name, ignoring case. An empty
+// name is the first class, or none at all if there aren't any.
+func findClass(cfg *config.Config, name string) (config.Class, bool) {
+	if name == "" && len(cfg.Classes) > 0 {
+		return cfg.Classes[0], true
+	}
+	for _, class := range cfg.Classes {
+		if strings.EqualFold(class.Name, name) {
+			return class, true
+		}
+	}
+	return config.Class{}, name == ""
+}
+
+// SetClass makes the player a different class from the config. Like
+// SetLoadout it is only meant for before the game starts.
+func (ge *GameEngine) SetClass(name string) error {
+	if ge.resumed {
+		return errors.New("can't change the class of a resumed game")
+	}
+	class, ok := findClass(ge.Config, name)
+	if !ok {
+		return fmt.Errorf("unknown class %q", name)
+	}
+
+	ge.player.setClass(class, ge.Config.PlayerHealth)
+	ge.Config.Class = class.Name
+	ge.replay = &Replay{Start: ge.Snapshot()}
+	ge.publish()
+	return nil
+}
+
+// Resumed reports whether the game carries on from a snapshot, so the class
+// and loadout were chosen when it started
+func (ge *GameEngine) Resumed() bool {
+	return ge.resumed
+}
+
+// SetRNG makes the game draw its random numbers from rng instead of the
+// seeded one it starts with. Saves and replays can only carry on the seeded
+// RNG, so Save and SaveReplay return an error once it is set.
+func (ge *GameEngine) SetRNG(rng RNG) {
+	ge.rng = rng
+	ge.customRNG = true
+}
+
+// spawnBee gives a new bee the next free ID and adds it to the hive
+func (ge *GameEngine) spawnBee(bee *Bee) {
+	bee.id = ge.nextBeeID
+	ge.nextBeeID++
+	ge.hive = append(ge.hive, bee)
+}
+
+// removeBee takes a bee out of the hive, keeping the rest in order
+func (ge *GameEngine) removeBee(id int) {
+	ge.hive = slices.DeleteFunc(ge.hive, func(bee *Bee) bool {
+		return bee.id == id
+	})
+}
+
+// Step plays a round of the game: the player's action, then the bees' turn
+// if the game isn't over. A stunned player loses their turn whatever the
+// action. It returns the events of the round and the state of the game after
+// it. An action that can't be taken is returned as an error without playing
+// the round, so the player can choose again.
+func (ge *GameEngine) Step(action Action) ([]Event, GameState, error) {
+	if ge.IsGameFinished() {
+		return nil, ge.State(), errors.New("the game is over")
+	}
+
+	if ge.playerTurn {
+		if ge.player.statuses.stacks(Stunned) > 0 {
+			ge.TurnsStunned++
+			ge.emit(Event{Type: PlayerStunned})
+		} else if err := ge.TakeAction(action); err != nil {
+			return ge.Events(), Running, err
+		}
+		// Poison can't take away a game the player has already won or fled
+		if !ge.IsGameFinished() {
+			ge.endPlayerTurn()
+		}
+		ge.playerTurn = false
+	}
+
+	if !ge.IsGameFinished() {
+		ge.TakeBeeTurn()
+		ge.playerTurn = true
+	}
+
+	state := ge.State()
+	if state != Running {
+		ge.emit(Event{Type: GameOver, State: state})
+	}
+	ge.publish()
+	return ge.Events(), state, nil
+}
+
+// Start runs the game over channels for a UI, a round at a time with Step.
+// Events go out on EventChan, in manual mode the player's commands come in
+// on InputChan, and the final state is sent on GameStateChan. Start returns
+// as soon as ctx is cancelled, even while waiting on a channel, and closes
+// Done when it does. It is only meant to be called once.
 func (ge *GameEngine) Start(auto bool, ctx context.Context) {
-	defer ctx.Done()
-	// Main game loop
+	err := ge.run(auto, ctx)
+
+	ge.mu.Lock()
+	ge.err = err
+	ge.mu.Unlock()
+	close(ge.done)
+}
+
+// Done returns a channel that is closed once Start has returned
+func (ge *GameEngine) Done() <-chan struct{} {
+	return ge.done
+}
+
+// Err returns why Start returned: nil if the game was played to the end, or
+// the context's error if it was cancelled. It is nil until Done is closed.
+func (ge *GameEngine) Err() error {
+	ge.mu.Lock()
+	defer ge.mu.Unlock()
+	return ge.err
+}
+
+// run is the game loop behind Start
+func (ge *GameEngine) run(auto bool, ctx context.Context) error {
+	ge.replay.Auto = auto
+
+	// The player sees everything so far before they type their command
+	input := func() (string, error) {
+		if err := ge.send(ctx, ge.Events()); err != nil {
+			return "", err
+		}
+		select {
+		case input := <-ge.InputChan:
+			return input, nil
+		case <-ctx.Done():
+			return "", ctx.Err()
+		}
+	}
+
 	for !ge.IsGameFinished() {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		events, err := ge.playRound(auto, input)
+		if err != nil {
+			return err
+		}
+		if err := ge.send(ctx, events); err != nil {
+			return err
+		}
+	}
+
+	select {
+	case ge.GameStateChan <- ge.State():
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// State returns how the game stands: still running, or how it ended
+func (ge *GameEngine) State() GameState {
+	switch {
+	case ge.fled:
+		return PlayerFled
+	case ge.player.IsDead():
+		return PlayerLose
+	case len(ge.hive) == 0:
+		return PlayerWin
+	}
+	return Running
+}
+
+// emit records an event in the replay and queues it for whoever is
+// consuming the game
+func (ge *GameEngine) emit(e Event) {
+	ge.replay.Events = append(ge.replay.Events, e)
+	ge.events = append(ge.events, e)
+}
+
+// Events returns the events queued since they were last taken by Events or
+// Step, for when the engine is driven with TakeAction and TakeBeeTurn
+func (ge *GameEngine) Events() []Event {
+	events := ge.events
+	ge.events = nil
+	return events
+}
+
+// send passes events out on EventChan, giving up if ctx is cancelled
+func (ge *GameEngine) send(ctx context.Context, events []Event) error {
+	for _, e := range events {
 		select {
+		case ge.EventChan <- e:
 		case <-ctx.Done():
-			return
-		default:
-			// Existing game logic
-			if ge.playerTurn {
-				if !auto {
-					ge.waitForPlayerAction()
-				}
-				ge.TakePlayerTurn()
-			} else {
-				ge.TakeBeeTurn()
-			}
+			return ctx.Err()
+		}
+	}
+	return nil
+}
 
-			// Toggle turn
-			ge.playerTurn = !ge.playerTurn
+// playRound plays a round with Step, asking input for the player's command
+// in manual mode, and returns its events. It is the game loop shared by Start
+// and RunReplay.
+func (ge *GameEngine) playRound(auto bool, input func() (string, error)) ([]Event, error) {
+	action := ge.AutoAction()
+	if !auto && ge.playerTurn && ge.player.statuses.stacks(Stunned) == 0 {
+		var err error
+		if action, err = ge.promptAction(input); err != nil {
+			return nil, err
 		}
 	}
 
-	// Send final game state messages
-	if ge.player.IsDead() {
-		ge.OutputChan <- "💀 You have been defeated by the hive!"
-		ge.GameStateChan <- PlayerLose
-	} else {
-		ge.OutputChan <- "🏆 Congratulations! You've destroyed the entire hive!"
-		ge.GameStateChan <- PlayerWin
+	events, _, err := ge.Step(action)
+	if err != nil {
+		ge.emit(Event{Type: InvalidCommand, Input: action.Target, Err: err.Error()})
+		events = append(events, ge.Events()...)
 	}
+	return events, nil
 }
 
-func (ge *GameEngine) waitForPlayerAction() {
+// promptAction prompts the player until they enter a valid action. Input is
+// only ever game actions: saving and loading are up to the client, so input
+// from a remote player can't reach the host's files.
+func (ge *GameEngine) promptAction(next func() (string, error)) (Action, error) {
+	ge.emit(Event{Type: PlayerPrompt})
 	for {
-		ge.OutputChan <- "Type 'hit' to attack..."
+		input, err := next()
+		if err != nil {
+			return Action{}, err
+		}
 
-		// Wait for valid input
-		input := <-ge.InputChan
-		if input == "hit" {
-			return
+		ge.replay.Commands = append(ge.replay.Commands, input)
+		action, err := ParseAction(input)
+		if err == nil {
+			err = ge.validateAction(action)
+		}
+		if err == nil {
+			return action, nil
 		}
 
-		ge.OutputChan <- fmt.Sprintf("Invalid command! '%s'", input)
+		ge.emit(Event{Type: InvalidCommand, Input: input, Err: err.Error()})
+		ge.emit(Event{Type: PlayerPrompt})
 	}
 }
 
 func (ge *GameEngine) IsGameFinished() bool {
-	if ge.player.IsDead() || len(ge.hive) == 0 {
+	if ge.fled || ge.player.IsDead() || len(ge.hive) == 0 {
 
 		return true
 	}
 	return false
 }
 
-// Wait for input chan to recieve a player input
+// TakeAction carries out the player's chosen action on their turn
+func (ge *GameEngine) TakeAction(action Action) error {
+	switch action.Type {
+	case ActionHit:
+		if action.Target == "" {
+			ge.TakePlayerTurn()
+			return nil
+		}
+
+		candidates, err := ge.targetCandidates(action.Target)
+		if err != nil {
+			return err
+		}
+		ge.hitBee(candidates, ge.Config.AimedMissPenalty)
+	case ActionDefend:
+		ge.defend()
+	case ActionDodge:
+		ge.dodge()
+	case ActionHeal:
+		return ge.heal()
+	case ActionFlee:
+		ge.flee()
+	case ActionUse:
+		return ge.useItem(action.Target)
+	case ActionSpecial:
+		return ge.special()
+	}
+	return nil
+}
+
+// AutoAction is what the player does in auto mode: their special ability
+// whenever it is ready, otherwise a random hit
+func (ge *GameEngine) AutoAction() Action {
+	if _, err := ge.readyAbility(); err == nil {
+		return Action{Type: ActionSpecial}
+	}
+	return Action{Type: ActionHit}
+}
+
+// TakePlayerTurn attacks a random bee from the hive
 func (ge *GameEngine) TakePlayerTurn() {
+	ge.hitBee(nil, 0)
+}
+
+// targetCandidates returns the hive positions a target could refer to. An
+// empty target means the whole hive, and numbers are bee IDs.
+func (ge *GameEngine) targetCandidates(target string) ([]int, error) {
+	if target == "" {
+		return nil, nil
+	}
+
+	if id, err := strconv.Atoi(target); err == nil {
+		pos := slices.IndexFunc(ge.hive, func(bee *Bee) bool {
+			return bee.id == id
+		})
+		if pos < 0 {
+			return nil, fmt.Errorf("there is no bee #%d", id)
+		}
+		return []int{pos}, nil
+	}
+
+	species, ok := ge.findSpecies(target)
+	if !ok {
+		return nil, fmt.Errorf("unknown target %q", target)
+	}
+
+	var candidates []int
+	for i, bee := range ge.hive {
+		if bee.beeType.String() == species.Name {
+			candidates = append(candidates, i)
+		}
+	}
+	if len(candidates) == 0 {
+		return nil, fmt.Errorf("there are no %s bees left", species.Name)
+	}
+	return candidates, nil
+}
+
+// hitBee attacks one of the candidate bees, or any bee in the hive when there
+// are no candidates. Aimed shots add a penalty to the player's miss chance.
+func (ge *GameEngine) hitBee(candidates []int, missPenalty float64) {
 	// Let the player Attack() to see if they miss
-	if !ge.player.Attack(ge.rng) {
-		ge.OutputChan <- "❌ Miss! You just missed the hive, better luck next time!"
+	if !ge.player.AimedAttack(ge.rng, missPenalty) {
+		ge.emit(Event{Type: PlayerMissed})
 		return
 	}
 
-	//Select a random bee from the hive and damage it
+	//Select a random bee from the candidates, weighted by how easy each is to
+	//hit, and damage it
 	ge.PlayerHits++
-	beePos := ge.rng.Intn(len(ge.hive))
+	beePos := 0
+	if candidates == nil {
+		beePos = ge.pickWeighted(ge.hive, byTargetWeight)
+	} else {
+		bees := make([]*Bee, len(candidates))
+		for i, pos := range candidates {
+			bees[i] = ge.hive[pos]
+		}
+		beePos = candidates[ge.pickWeighted(bees, byTargetWeight)]
+	}
 	bee := ge.hive[beePos]
 
+	// Guards may throw themselves in front of an aimed shot at a leader
+	if candidates != nil {
+		for _, b := range ge.hive {
+			if behaviourOf(b).Intercepts(ge, b, bee) {
+				ge.emit(Event{Type: BeeGuarded, BeeType: b.beeType, BeeID: b.id, OtherType: bee.beeType, OtherID: bee.id})
+				bee = b
+				break
+			}
+		}
+	}
+
 	// Deal damage to the bee
-	beeDamage := bee.Hit()
-	ge.OutputChan <- fmt.Sprintf("🧑 Direct Hit! You dealt %d damage to a %s Bee.", beeDamage, bee.beeType)
+	beeDamage, crit := ge.player.RollCrit(ge.rng, ge.player.HitDamage(bee.RollHitDamage(ge.rng), bee.beeType))
+	bee.Hit(beeDamage)
+	ge.emit(Event{Type: BeeHit, BeeType: bee.beeType, BeeID: bee.id, Damage: beeDamage, HP: bee.hp, Critical: crit})
+	ge.checkBeeDead(bee)
+}
 
-	// Check if bee is dead and which type of bee to update the hive
-	if bee.IsDead() && bee.beeType == QueenBee {
-		ge.OutputChan <- "🎉 The Queen Bee is dead, and the entire hive collapses!"
+// checkBeeDead takes a dead bee out of the hive, or the whole hive if it was
+// a leader and the collapse rule says so
+func (ge *GameEngine) checkBeeDead(bee *Bee) {
+	if !bee.IsDead() {
+		return
+	}
+
+	ge.removeBee(bee.id)
+	if bee.leader && ge.hiveCollapses() {
+		ge.emit(Event{Type: HiveCollapsed, BeeType: bee.beeType, BeeID: bee.id})
 		ge.ClearHive()
-	} else if bee.IsDead() {
-		ge.OutputChan <- fmt.Sprintf("💀 You killed a %s!", bee.beeType)
+		return
+	}
 
-		// Swap with the last element and shrink the slice
-		ge.hive[beePos] = ge.hive[len(ge.hive)-1]
-		ge.hive = ge.hive[:len(ge.hive)-1]
+	ge.emit(Event{Type: BeeKilled, BeeType: bee.beeType, BeeID: bee.id})
+	ge.enrage(bee)
+}
+
+// hiveCollapses checks the collapse rule once a leader has died
+func (ge *GameEngine) hiveCollapses() bool {
+	switch ge.Config.CollapseRule {
+	case config.CollapseNever:
+		return false
+	case config.CollapseLast:
+		return !slices.ContainsFunc(ge.hive, func(bee *Bee) bool {
+			return bee.leader
+		})
 	}
+	return true
 }
 
 func (ge *GameEngine) TakeBeeTurn() {
-	// Select random bee from the hive
-	beePos := ge.rng.Intn(len(ge.hive))
-	bee := ge.hive[beePos]
+	defer ge.endBeeTurn()
+
+	// Pacified bees sit the turn out
+	if ge.pacifiedTurns > 0 {
+		ge.pacifiedTurns--
+		ge.emit(Event{Type: BeesPacified, Turns: ge.pacifiedTurns})
+		return
+	}
+
+	// Let each bee in the swarm take its turn
+	hpBefore, stingsBefore := ge.player.hp, ge.BeeStings
+	swarm := ge.swarm()
+	for _, bee := range swarm {
+		if ge.player.IsDead() {
+			break
+		}
+		behaviourOf(bee).TakeTurn(ge, bee)
+	}
+
+	// Sum up the damage when more than one bee attacked
+	if len(swarm) > 1 {
+		ge.emit(Event{Type: SwarmAttacked, Count: ge.BeeStings - stingsBefore, Damage: hpBefore - ge.player.hp, HP: ge.player.hp})
+	}
+}
+
+// swarm picks the bees that act on this bee turn
+func (ge *GameEngine) swarm() []*Bee {
+	switch ge.Config.SwarmMode {
+	case config.SwarmPerType:
+		var bees []*Bee
+		for _, species := range ge.Config.Species {
+			var kind []*Bee
+			for _, bee := range ge.hive {
+				if bee.beeType.String() == species.Name {
+					kind = append(kind, bee)
+				}
+			}
+			if len(kind) > 0 {
+				bees = append(bees, kind[ge.rng.Intn(len(kind))])
+			}
+		}
+		return bees
+	case config.SwarmProportional:
+		return ge.pickBees(int(math.Ceil(float64(len(ge.hive))*ge.Config.SwarmRatio)), byAttackWeight)
+	}
+	return ge.pickBees(ge.Config.SwarmSize, byAttackWeight)
+}
+
+// pickBees picks n different bees from the hive at random by weight, at least
+// one and at most the whole hive
+func (ge *GameEngine) pickBees(n int, weight func(*Bee) float64) []*Bee {
+	n = min(max(n, 1), len(ge.hive))
+	bees := slices.Clone(ge.hive)
+	for i := 0; i < n; i++ {
+		j := i + ge.pickWeighted(bees[i:], weight)
+		bees[i], bees[j] = bees[j], bees[i]
+	}
+	return bees[:n]
+}
+
+// pickWeighted picks the position of one of the bees at random, in proportion
+// to its weight. Equal weights are a plain uniform pick.
+func (ge *GameEngine) pickWeighted(bees []*Bee, weight func(*Bee) float64) int {
+	total, uniform := 0.0, true
+	for _, bee := range bees {
+		total += weight(bee)
+		uniform = uniform && weight(bee) == weight(bees[0])
+	}
+	if uniform {
+		return ge.rng.Intn(len(bees))
+	}
+
+	roll := ge.rng.Float64() * total
+	for i, bee := range bees {
+		roll -= weight(bee)
+		if roll < 0 {
+			return i
+		}
+	}
+	return len(bees) - 1
+}
 
-	// Let the bee Attack() to get damage
-	damage := bee.Attack(ge.rng)
+// sting has the bee attack the player
+func (ge *GameEngine) sting(bee *Bee) {
+	// Let the bee Attack() to get damage, dodging makes it more likely to miss
+	damage, crit := bee.DodgedAttack(ge.rng, ge.player.missBonus(ge.Config.DodgeBonus))
 	if damage == 0 {
-		ge.OutputChan <- fmt.Sprintf("❌ Buzz! That was close! The %s Bee just missed you!", bee.beeType)
+		ge.emit(Event{Type: BeeMissed, BeeType: bee.beeType, BeeID: bee.id})
 		return
 	}
 
+	// Enraged bees sting harder
+	if stacks := bee.statuses.stacks(Enraged); stacks > 0 {
+		damage = int(math.Round(float64(damage) * (1 + ge.Config.Enrage.Bonus*float64(stacks))))
+	}
+
+	// Armor takes the edge off every sting
+	damage, blocked := ge.player.Armor(damage)
+	ge.DamageBlocked += blocked
+
+	// Defending blocks part of the sting
+	if ge.player.defending {
+		defended := int(math.Round(float64(damage) * ge.Config.DefendReduction))
+		damage -= defended
+		blocked += defended
+		ge.DamageBlocked += defended
+	}
+
+	// So does an antihistamine, for as long as it lasts
+	if ge.player.resistTurns > 0 {
+		resisted := int(math.Round(float64(damage) * ge.player.resist))
+		damage -= resisted
+		blocked += resisted
+		ge.DamageBlocked += resisted
+	}
+
 	// Run Sting() on player
 	ge.player.Sting(damage)
 	ge.BeeStings++
 
 	// Send out response from game to cli
-	ge.OutputChan <- fmt.Sprintf("🐝 Ouch! A %s Bee stung you for %d damage!", bee.beeType, damage)
+	ge.emit(Event{Type: BeeStung, BeeType: bee.beeType, BeeID: bee.id, Damage: damage, Blocked: blocked, HP: ge.player.hp, Critical: crit})
+
+	if !ge.player.IsDead() {
+		ge.afflict(bee)
+	}
+}
+
+// endBeeTurn wraps up a round of the game once the bees have had their turn
+func (ge *GameEngine) endBeeTurn() {
+	// Defending and dodging only last for this bee turn
+	ge.player.endBeeTurn()
+	for _, bee := range ge.hive {
+		bee.statuses.tick()
+	}
+
+	ge.turn++
+	if !ge.IsGameFinished() {
+		ge.layEggs()
+	}
+}
+
+// layEggs adds a new bee to the hive for every bee due to spawn one this
+// turn, until the hive is full
+func (ge *GameEngine) layEggs() {
+	// Only bees that were there at the start of the turn lay eggs, as range
+	// doesn't see the bees added by spawnBee
+	for _, parent := range ge.hive {
+		if parent.spawns == "" || parent.spawnInterval <= 0 || ge.turn%parent.spawnInterval != 0 {
+			continue
+		}
+		if ge.Config.MaxHiveSize > 0 && len(ge.hive) >= ge.Config.MaxHiveSize {
+			return
+		}
+
+		species, ok := ge.findSpecies(parent.spawns.String())
+		if !ok {
+			continue
+		}
+		bee := newBee(species)
+		ge.spawnBee(bee)
+		ge.emit(Event{Type: BeeSpawned, BeeType: bee.beeType, BeeID: bee.id, HP: bee.hp})
+	}
 }
@@ -2,8 +2,11 @@ package game_test
 
 import (
 	"context"
+	"errors"
 	"fmt"
-	"strings"
+	"path/filepath"
+	"reflect"
+	"runtime"
 	"testing"
 	"time"
 
@@ -14,22 +17,14 @@ import (
 func TestNewGame(t *testing.T) {
 	// Test with default config
 	cfg := &config.Config{
-		PlayerHealth:          100,
-		PlayerMissChance:      0.2,
-		WorkerBeeAmount:       10,
-		WorkerBeeHealth:       10,
-		WorkerBeeAttackDamage: 5,
-		WorkerBeeHitDamage:    8,
-		DroneBeeAmount:        5,
-		DroneBeeHealth:        20,
-		DroneBeeAttackDamage:  7,
-		DroneBeeHitDamage:     10,
-		QueenBeeAmount:        1,
-		QueenBeeHealth:        50,
-		QueenBeeAttackDamage:  12,
-		QueenBeeHitDamage:     15,
-		BeeMissChance:         0.3,
-		RandomSeed:            12345,
+		PlayerHealth:     100,
+		PlayerMissChance: 0.2,
+		RandomSeed:       12345,
+		Species: []config.BeeSpecies{
+			{Name: "Worker", Amount: 10, Health: 10, AttackDamage: 5, HitDamage: 8, MissChance: 0.3},
+			{Name: "Drone", Amount: 5, Health: 20, AttackDamage: 7, HitDamage: 10, MissChance: 0.3},
+			{Name: "Queen", Amount: 1, Health: 50, AttackDamage: 12, HitDamage: 15, MissChance: 0.3, Leader: true},
+		},
 	}
 
 	ge := game.NewGame(cfg)
@@ -40,7 +35,10 @@ func TestNewGame(t *testing.T) {
 	}
 
 	// Verify hive size matches config
-	expectedBeeCount := cfg.WorkerBeeAmount + cfg.DroneBeeAmount + cfg.QueenBeeAmount
+	expectedBeeCount := 0
+	for _, species := range cfg.Species {
+		expectedBeeCount += species.Amount
+	}
 	actualBeeCount := len(ge.GetHive())
 	if actualBeeCount != expectedBeeCount {
 		t.Errorf("Expected hive size %d, got %d", expectedBeeCount, actualBeeCount)
@@ -50,9 +48,10 @@ func TestNewGame(t *testing.T) {
 	cfg = &config.Config{
 		PlayerHealth:     10,
 		PlayerMissChance: 0.2,
-		WorkerBeeAmount:  1,
-		BeeMissChance:    0.3,
 		RandomSeed:       12345,
+		Species: []config.BeeSpecies{
+			{Name: "Worker", Amount: 1, MissChance: 0.3},
+		},
 	}
 
 	ge = game.NewGame(cfg)
@@ -63,7 +62,7 @@ func TestNewGame(t *testing.T) {
 	}
 
 	// Check custom hive size
-	expectedBeeCount = cfg.WorkerBeeAmount + cfg.DroneBeeAmount + cfg.QueenBeeAmount
+	expectedBeeCount = cfg.Species[0].Amount
 	actualBeeCount = len(ge.GetHive())
 
 	if actualBeeCount != expectedBeeCount {
@@ -101,10 +100,11 @@ func TestHasGameFinished(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			cfg := &config.Config{
-				PlayerHealth:    tt.playerHealth,
-				WorkerBeeAmount: tt.hiveSize,
-				WorkerBeeHealth: 1,
-				RandomSeed:      12345,
+				PlayerHealth: tt.playerHealth,
+				RandomSeed:   12345,
+				Species: []config.BeeSpecies{
+					{Name: "Worker", Amount: tt.hiveSize, Health: 1},
+				},
 			}
 
 			ge := game.NewGame(cfg)
@@ -127,43 +127,27 @@ func TestHasGameFinished(t *testing.T) {
 
 func TestTakePlayerTurn(t *testing.T) {
 	cfg := &config.Config{
-		PlayerHealth:          100,
-		PlayerMissChance:      0,
-		WorkerBeeAmount:       1,
-		WorkerBeeHealth:       10,
-		WorkerBeeAttackDamage: 5,
-		WorkerBeeHitDamage:    8,
-		BeeMissChance:         0,
-		RandomSeed:            42,
+		PlayerHealth:     100,
+		PlayerMissChance: 0,
+		RandomSeed:       42,
+		Species: []config.BeeSpecies{
+			{Name: "Worker", Amount: 1, Health: 10, AttackDamage: 5, HitDamage: 8},
+		},
 	}
 
 	ge := game.NewGame(cfg)
 
-	// Start a goroutine to read from OutputChan
-	outputMessages := make([]string, 0)
-	done := make(chan bool)
-	go func() {
-		for {
-			select {
-			case msg := <-ge.OutputChan:
-				outputMessages = append(outputMessages, msg)
-			case <-done:
-				return
-			}
-		}
-	}()
-
 	ge.TakePlayerTurn()
-	done <- true
+	events := ge.Events()
 
 	// Verify player hit a bee
-	if len(outputMessages) == 0 {
-		t.Fatalf("No output messages received")
+	if len(events) == 0 {
+		t.Fatalf("No events received")
 	}
 
-	// Check if the first message indicates a hit
-	if !strings.Contains(outputMessages[0], "Direct Hit!") {
-		t.Errorf("Expected player to hit, got message: %s", outputMessages[0])
+	// Check if the first event is a hit
+	if events[0].Type != game.BeeHit || events[0].Damage != 8 {
+		t.Errorf("Expected player to hit for 8, got event: %+v", events[0])
 	}
 
 	// Validate damage was dealt to the bee
@@ -174,44 +158,28 @@ func TestTakePlayerTurn(t *testing.T) {
 
 func TestTakeBeeTurn(t *testing.T) {
 	cfg := &config.Config{
-		PlayerHealth:          100,
-		PlayerMissChance:      0,
-		WorkerBeeAmount:       1,
-		WorkerBeeHealth:       10,
-		WorkerBeeAttackDamage: 5,
-		WorkerBeeHitDamage:    8,
-		BeeMissChance:         0,
-		RandomSeed:            42,
+		PlayerHealth:     100,
+		PlayerMissChance: 0,
+		RandomSeed:       42,
+		Species: []config.BeeSpecies{
+			{Name: "Worker", Amount: 1, Health: 10, AttackDamage: 5, HitDamage: 8},
+		},
 	}
 
 	ge := game.NewGame(cfg)
 
-	// Start a goroutine to read from OutputChan
-	outputMessages := make([]string, 0)
-	done := make(chan bool)
-	go func() {
-		for {
-			select {
-			case msg := <-ge.OutputChan:
-				outputMessages = append(outputMessages, msg)
-			case <-done:
-				return
-			}
-		}
-	}()
-
 	// Take a bee turn
 	ge.TakeBeeTurn()
-	done <- true
+	events := ge.Events()
 
 	// Verify bee stung the player
-	if len(outputMessages) == 0 {
-		t.Fatalf("No output messages received")
+	if len(events) == 0 {
+		t.Fatalf("No events received")
 	}
 
-	// Check if the message indicates a sting
-	if !strings.Contains(outputMessages[0], "Ouch!") {
-		t.Errorf("Expected bee to sting, got message: %s", outputMessages[0])
+	// Check if the event is a sting from the worker
+	if events[0].Type != game.BeeStung || events[0].BeeType != game.WorkerBee || events[0].Damage != 5 {
+		t.Errorf("Expected worker bee to sting for 5, got event: %+v", events[0])
 	}
 
 	// Validate damage was dealt to the player
@@ -222,32 +190,16 @@ func TestTakeBeeTurn(t *testing.T) {
 
 func TestQueenBeeKill(t *testing.T) {
 	cfg := &config.Config{
-		PlayerHealth:         100,
-		PlayerMissChance:     0, // Ensure player never misses
-		QueenBeeAmount:       1,
-		QueenBeeHealth:       1, // One hit will kill
-		QueenBeeAttackDamage: 10,
-		QueenBeeHitDamage:    10,
-		BeeMissChance:        0,
-		RandomSeed:           12345,
+		PlayerHealth:     100,
+		PlayerMissChance: 0, // Ensure player never misses
+		RandomSeed:       12345,
+		Species: []config.BeeSpecies{
+			{Name: "Queen", Amount: 1, Health: 1, AttackDamage: 10, HitDamage: 10, Leader: true}, // One hit will kill
+		},
 	}
 
 	ge := game.NewGame(cfg)
 
-	// Start a goroutine to read from OutputChan
-	outputMessages := make([]string, 0)
-	done := make(chan bool)
-	go func() {
-		for {
-			select {
-			case msg := <-ge.OutputChan:
-				outputMessages = append(outputMessages, msg)
-			case <-done:
-				return
-			}
-		}
-	}()
-
 	// Take a player turn which should kill the queen
 	ge.TakePlayerTurn()
 
@@ -255,8 +207,7 @@ func TestQueenBeeKill(t *testing.T) {
 		t.Errorf("Game should have ended when Queen Bee died")
 	}
 
-	// Stop reading from OutputChan
-	done <- true
+	events := ge.Events()
 
 	// Verify queen was killed and hive collapsed
 	hiveSize := len(ge.GetHive())
@@ -264,30 +215,28 @@ func TestQueenBeeKill(t *testing.T) {
 		t.Errorf("Expected hive to collapse (size 0), got size %d", hiveSize)
 	}
 
-	// Check for queen death message
-	queenDeathMessageFound := false
-	for _, msg := range outputMessages {
-		if strings.Contains(msg, "The Queen Bee is dead, and the entire hive collapses!") {
-			queenDeathMessageFound = true
+	// Check for hive collapse event
+	hiveCollapsedFound := false
+	for _, event := range events {
+		if event.Type == game.HiveCollapsed && event.BeeType == game.QueenBee {
+			hiveCollapsedFound = true
 			break
 		}
 	}
 
-	if !queenDeathMessageFound {
-		t.Errorf("Queen death message not found in output")
+	if !hiveCollapsedFound {
+		t.Errorf("HiveCollapsed event not found in output")
 	}
 }
 
 func TestGameLoop(t *testing.T) {
 	cfg := &config.Config{
-		PlayerHealth:          10,
-		PlayerMissChance:      0,
-		WorkerBeeAmount:       1,
-		WorkerBeeHealth:       1,
-		WorkerBeeAttackDamage: 5,
-		WorkerBeeHitDamage:    1,
-		BeeMissChance:         0,
-		RandomSeed:            12345,
+		PlayerHealth:     10,
+		PlayerMissChance: 0,
+		RandomSeed:       12345,
+		Species: []config.BeeSpecies{
+			{Name: "Worker", Amount: 1, Health: 1, AttackDamage: 5, HitDamage: 1},
+		},
 	}
 
 	ge := game.NewGame(cfg)
@@ -297,8 +246,8 @@ func TestGameLoop(t *testing.T) {
 	defer cancel()
 
 	go func() {
-		for output := range ge.OutputChan {
-			fmt.Println(output)
+		for event := range ge.EventChan {
+			fmt.Println(event.Type)
 		}
 	}()
 
@@ -320,3 +269,815 @@ func TestGameLoop(t *testing.T) {
 		t.Errorf("Expected game to be finished")
 	}
 }
+
+func TestAimedPlayerTurn(t *testing.T) {
+	cfg := &config.Config{
+		PlayerHealth:     100,
+		PlayerMissChance: 0,
+		AimedMissPenalty: 0,
+		RandomSeed:       12345,
+		Species: []config.BeeSpecies{
+			{Name: "Worker", Amount: 5, Health: 50, HitDamage: 10},
+			{Name: "Queen", Amount: 1, Health: 100, HitDamage: 10, Leader: true},
+		},
+	}
+
+	ge := game.NewGame(cfg)
+
+	// Aim at the queen by type
+	if err := ge.TakeAction(game.Action{Type: game.ActionHit, Target: "queen"}); err != nil {
+		t.Fatalf("TakeAction() returned error: %v", err)
+	}
+	for _, bee := range ge.GetHive() {
+		if bee.GetBeeType() == game.QueenBee && bee.GetHP() != 90 {
+			t.Errorf("Expected queen health to be 90, got %d", bee.GetHP())
+		}
+	}
+
+	// Aim at the first bee by number
+	if err := ge.TakeAction(game.Action{Type: game.ActionHit, Target: "1"}); err != nil {
+		t.Fatalf("TakeAction() returned error: %v", err)
+	}
+	if ge.GetHive()[0].GetHP() != 40 {
+		t.Errorf("Expected bee #1 health to be 40, got %d", ge.GetHive()[0].GetHP())
+	}
+
+	// Targets that don't exist are rejected
+	for _, target := range []string{"0", "7", "drone", "wasp"} {
+		if err := ge.TakeAction(game.Action{Type: game.ActionHit, Target: target}); err == nil {
+			t.Errorf("Expected error aiming at %q", target)
+		}
+	}
+}
+
+func TestAimedMissPenalty(t *testing.T) {
+	cfg := &config.Config{
+		PlayerHealth:     100,
+		PlayerMissChance: 0,
+		AimedMissPenalty: 1,
+		RandomSeed:       12345,
+		Species: []config.BeeSpecies{
+			{Name: "Worker", Amount: 1, Health: 50},
+		},
+	}
+
+	ge := game.NewGame(cfg)
+
+	ge.TakeAction(game.Action{Type: game.ActionHit, Target: "worker"})
+	events := ge.Events()
+
+	if len(events) == 0 || events[0].Type != game.PlayerMissed {
+		t.Errorf("Expected aimed shot with full penalty to miss, got %+v", events)
+	}
+}
+
+func TestStableBeeIDs(t *testing.T) {
+	cfg := &config.Config{
+		PlayerHealth:     100,
+		PlayerMissChance: 0,
+		RandomSeed:       12345,
+		Species: []config.BeeSpecies{
+			{Name: "Worker", Amount: 3, Health: 10, HitDamage: 10},
+		},
+	}
+
+	ge := game.NewGame(cfg)
+
+	// Kill the first bee, the others should keep their IDs and order
+	ge.TakeAction(game.Action{Type: game.ActionHit, Target: "1"})
+	events := ge.Events()
+
+	if _, ok := ge.GetBee(1); ok {
+		t.Errorf("Expected bee #1 to be gone from the hive")
+	}
+
+	for i, bee := range ge.GetHive() {
+		if bee.GetID() != i+2 {
+			t.Errorf("Expected bee at position %d to have ID %d, got %d", i, i+2, bee.GetID())
+		}
+	}
+
+	if bee, ok := ge.GetBee(3); !ok || bee.GetHP() != 10 {
+		t.Errorf("Expected to find untouched bee #3, got %v", bee)
+	}
+
+	// Events should reference the bee that was hit
+	if len(events) < 2 || events[0].BeeID != 1 || events[0].HP != 0 || events[1].Type != game.BeeKilled || events[1].BeeID != 1 {
+		t.Errorf("Expected hit and kill events for bee #1, got %+v", events)
+	}
+}
+
+func TestCustomSpecies(t *testing.T) {
+	cfg := &config.Config{
+		PlayerHealth:     100,
+		PlayerMissChance: 0,
+		RandomSeed:       12345,
+		Species: []config.BeeSpecies{
+			{Name: "Guard", Amount: 2, Health: 50, HitDamage: 10},
+			{Name: "Matriarch", Amount: 1, Health: 10, HitDamage: 10, Leader: true},
+		},
+	}
+
+	ge := game.NewGame(cfg)
+
+	if len(ge.GetHive()) != 3 || ge.GetHive()[0].GetBeeType() != "Guard" {
+		t.Fatalf("Expected hive of 2 guards and a matriarch, got %d bees", len(ge.GetHive()))
+	}
+
+	// Species names can be targeted in any case
+	if err := ge.TakeAction(game.Action{Type: game.ActionHit, Target: "GUARD"}); err != nil {
+		t.Fatalf("TakeAction() returned error: %v", err)
+	}
+
+	// Killing the leader collapses the hive
+	if err := ge.TakeAction(game.Action{Type: game.ActionHit, Target: "matriarch"}); err != nil {
+		t.Fatalf("TakeAction() returned error: %v", err)
+	}
+	if len(ge.GetHive()) != 0 {
+		t.Errorf("Expected hive to collapse when the leader died, got %d bees", len(ge.GetHive()))
+	}
+}
+
+func TestDefendDodgeAndHeal(t *testing.T) {
+	cfg := &config.Config{
+		PlayerHealth:     100,
+		PlayerMissChance: 0,
+		DefendReduction:  0.5,
+		DodgeBonus:       1,
+		HealAmount:       30,
+		HealUses:         1,
+		RandomSeed:       12345,
+		Species: []config.BeeSpecies{
+			{Name: "Worker", Amount: 1, Health: 50, AttackDamage: 10, HitDamage: 10},
+		},
+	}
+
+	ge := game.NewGame(cfg)
+
+	// Defending halves the next sting only
+	ge.TakeAction(game.Action{Type: game.ActionDefend})
+	ge.TakeBeeTurn()
+	if ge.GetPlayer().GetHP() != 95 {
+		t.Errorf("Expected defended sting to leave 95 HP, got %d", ge.GetPlayer().GetHP())
+	}
+	ge.TakeBeeTurn()
+	if ge.GetPlayer().GetHP() != 85 {
+		t.Errorf("Expected undefended sting to leave 85 HP, got %d", ge.GetPlayer().GetHP())
+	}
+
+	// Dodging with a bonus of 1 always makes the bee miss
+	ge.TakeAction(game.Action{Type: game.ActionDodge})
+	ge.TakeBeeTurn()
+	if ge.GetPlayer().GetHP() != 85 {
+		t.Errorf("Expected dodged sting to miss, got %d HP", ge.GetPlayer().GetHP())
+	}
+
+	// Healing can't go over the max HP and is limited
+	if err := ge.TakeAction(game.Action{Type: game.ActionHeal}); err != nil {
+		t.Fatalf("TakeAction(heal) returned error: %v", err)
+	}
+	if ge.GetPlayer().GetHP() != 100 || ge.GetPlayer().GetHealsLeft() != 0 {
+		t.Errorf("Expected heal to cap at 100 HP with no heals left, got %d HP and %d heals", ge.GetPlayer().GetHP(), ge.GetPlayer().GetHealsLeft())
+	}
+	if err := ge.TakeAction(game.Action{Type: game.ActionHeal}); err == nil {
+		t.Errorf("Expected error healing with no heals left")
+	}
+	events := ge.Events()
+
+	if ge.PlayerDefends != 1 || ge.PlayerDodges != 1 || ge.PlayerHeals != 1 || ge.DamageBlocked != 5 {
+		t.Errorf("Unexpected stats: defends %d, dodges %d, heals %d, blocked %d", ge.PlayerDefends, ge.PlayerDodges, ge.PlayerHeals, ge.DamageBlocked)
+	}
+
+	healed := false
+	for _, event := range events {
+		if event.Type == game.PlayerHealed && event.Healed == 15 && event.HP == 100 {
+			healed = true
+		}
+	}
+	if !healed {
+		t.Errorf("Expected PlayerHealed event for 15 HP, got %+v", events)
+	}
+}
+
+func TestFlee(t *testing.T) {
+	cfg := &config.Config{
+		PlayerHealth: 100,
+		RandomSeed:   12345,
+		Species: []config.BeeSpecies{
+			{Name: "Worker", Amount: 1, Health: 50, AttackDamage: 10, HitDamage: 10},
+		},
+	}
+
+	ge := game.NewGame(cfg)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 1*time.Second)
+	defer cancel()
+	go ge.Start(false, ctx)
+
+	for event := range ge.EventChan {
+		if event.Type == game.PlayerPrompt {
+			ge.InputChan <- "flee"
+		}
+		if event.Type == game.GameOver {
+			if event.State != game.PlayerFled {
+				t.Errorf("Expected game to end with PlayerFled, got %v", event.State)
+			}
+			break
+		}
+	}
+
+	if state := <-ge.GameStateChan; state != game.PlayerFled {
+		t.Errorf("Expected PlayerFled game state, got %v", state)
+	}
+}
+
+func TestUseItems(t *testing.T) {
+	cfg := &config.Config{
+		PlayerHealth: 100,
+		RandomSeed:   12345,
+		Species: []config.BeeSpecies{
+			{Name: "Worker", Amount: 3, Health: 50, AttackDamage: 10, HitDamage: 10},
+			{Name: "Queen", Amount: 1, Health: 100, AttackDamage: 10, HitDamage: 10, Leader: true},
+		},
+		Items: []config.Item{
+			{Name: "smoke", Effect: config.EffectPacify, Quantity: 1, Turns: 2},
+			{Name: "spray", Effect: config.EffectAreaDamage, Quantity: 1, Amount: 20, Targets: 2},
+			{Name: "antihistamine", Effect: config.EffectHeal, Quantity: 1, Amount: 5, Turns: 1, Resist: 0.5},
+		},
+	}
+
+	ge := game.NewGame(cfg)
+
+	// Smoke stops the bees attacking for two turns, then wears off
+	if err := ge.TakeAction(game.Action{Type: game.ActionUse, Target: "smoke"}); err != nil {
+		t.Fatalf("TakeAction(use smoke) returned error: %v", err)
+	}
+	ge.TakeBeeTurn()
+	ge.TakeBeeTurn()
+	if ge.GetPlayer().GetHP() != 100 {
+		t.Errorf("Expected pacified bees not to sting, got %d HP", ge.GetPlayer().GetHP())
+	}
+	ge.TakeBeeTurn()
+	if ge.GetPlayer().GetHP() != 90 {
+		t.Errorf("Expected bees to sting once the smoke wears off, got %d HP", ge.GetPlayer().GetHP())
+	}
+
+	// Spray hits two different bees
+	if err := ge.TakeAction(game.Action{Type: game.ActionUse, Target: "spray"}); err != nil {
+		t.Fatalf("TakeAction(use spray) returned error: %v", err)
+	}
+	hurt := 0
+	for _, bee := range ge.GetHive() {
+		if bee.GetHP() < 50 || (bee.IsLeader() && bee.GetHP() < 100) {
+			hurt++
+		}
+	}
+	if hurt != 2 {
+		t.Errorf("Expected spray to hurt 2 bees, got %d", hurt)
+	}
+
+	// Antihistamine heals and halves the next sting
+	if err := ge.TakeAction(game.Action{Type: game.ActionUse, Target: "antihistamine"}); err != nil {
+		t.Fatalf("TakeAction(use antihistamine) returned error: %v", err)
+	}
+	ge.TakeBeeTurn()
+	if ge.GetPlayer().GetHP() != 90 {
+		t.Errorf("Expected healed 95 HP less a resisted sting of 5, got %d HP", ge.GetPlayer().GetHP())
+	}
+
+	// Every item has been used up
+	if err := ge.TakeAction(game.Action{Type: game.ActionUse, Target: "smoke"}); err == nil {
+		t.Errorf("Expected error using smoke with none left")
+	}
+	if err := ge.TakeAction(game.Action{Type: game.ActionUse, Target: "honey"}); err == nil {
+		t.Errorf("Expected error using an unknown item")
+	}
+	events := ge.Events()
+
+	if ge.ItemsUsed != 3 || ge.GetPlayer().GetItemCount("spray") != 0 {
+		t.Errorf("Expected 3 items used and no spray left, got %d used and %d spray", ge.ItemsUsed, ge.GetPlayer().GetItemCount("spray"))
+	}
+
+	pacified := 0
+	for _, event := range events {
+		if event.Type == game.BeesPacified {
+			pacified++
+		}
+	}
+	if pacified != 2 {
+		t.Errorf("Expected 2 BeesPacified events, got %d", pacified)
+	}
+}
+
+func TestQueenLaysEggs(t *testing.T) {
+	cfg := &config.Config{
+		PlayerHealth: 100,
+		MaxHiveSize:  4,
+		RandomSeed:   12345,
+		Species: []config.BeeSpecies{
+			{Name: "Queen", Amount: 1, Health: 100, HitDamage: 10, MissChance: 1, Leader: true, Spawns: "Drone", SpawnInterval: 2},
+			{Name: "Drone", Amount: 1, Health: 60, HitDamage: 30, MissChance: 1},
+		},
+	}
+
+	ge := game.NewGame(cfg)
+
+	// A drone every other turn, until the hive is full
+	expected := []int{2, 3, 3, 4, 4, 4, 4}
+	for turn, want := range expected {
+		ge.TakeBeeTurn()
+		if len(ge.GetHive()) != want {
+			t.Errorf("Expected %d bees after turn %d, got %d", want, turn+1, len(ge.GetHive()))
+		}
+	}
+	events := ge.Events()
+
+	spawned := []int{}
+	for _, event := range events {
+		if event.Type == game.BeeSpawned {
+			if event.BeeType != game.DroneBee {
+				t.Errorf("Expected the queen to lay drones, got %s", event.BeeType)
+			}
+			spawned = append(spawned, event.BeeID)
+		}
+	}
+	if !reflect.DeepEqual(spawned, []int{3, 4}) {
+		t.Errorf("Expected new drones #3 and #4, got %v", spawned)
+	}
+}
+
+func TestSwarmModes(t *testing.T) {
+	tests := []struct {
+		name     string
+		mode     string
+		size     int
+		ratio    float64
+		expected int
+	}{
+		{"Single bee by default", "", 0, 0, 1},
+		{"Fixed size", config.SwarmFixed, 3, 0, 3},
+		{"Fixed size bigger than the hive", config.SwarmFixed, 20, 0, 6},
+		{"Proportional rounds up", config.SwarmProportional, 0, 0.4, 3},
+		{"One per type", config.SwarmPerType, 0, 0, 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &config.Config{
+				PlayerHealth: 100,
+				SwarmMode:    tt.mode,
+				SwarmSize:    tt.size,
+				SwarmRatio:   tt.ratio,
+				RandomSeed:   12345,
+				Species: []config.BeeSpecies{
+					{Name: "Worker", Amount: 5, Health: 50, AttackDamage: 2, HitDamage: 10},
+					{Name: "Queen", Amount: 1, Health: 100, AttackDamage: 10, HitDamage: 10, Leader: true},
+				},
+			}
+
+			ge := game.NewGame(cfg)
+
+			ge.TakeBeeTurn()
+			events := ge.Events()
+
+			stings, damage := 0, 0
+			stung := map[int]bool{}
+			var summary *game.Event
+			for i, event := range events {
+				switch event.Type {
+				case game.BeeStung:
+					stings++
+					damage += event.Damage
+					stung[event.BeeID] = true
+				case game.SwarmAttacked:
+					summary = &events[i]
+				}
+			}
+
+			if stings != tt.expected || len(stung) != tt.expected {
+				t.Errorf("Expected %d different bees to sting, got %d stings from %d bees", tt.expected, stings, len(stung))
+			}
+			if 100-ge.GetPlayer().GetHP() != damage {
+				t.Errorf("Expected player to take %d damage, got %d HP", damage, ge.GetPlayer().GetHP())
+			}
+
+			if tt.expected == 1 && summary != nil {
+				t.Errorf("Expected no swarm summary for a single bee, got %+v", *summary)
+			}
+			if tt.expected > 1 && (summary == nil || summary.Count != stings || summary.Damage != damage) {
+				t.Errorf("Expected swarm summary of %d stings for %d damage, got %+v", stings, damage, summary)
+			}
+		})
+	}
+}
+
+func TestStunSkipsTurn(t *testing.T) {
+	cfg := &config.Config{
+		PlayerHealth: 30,
+		Stun:         config.StatusEffect{Turns: 1},
+		RandomSeed:   12345,
+		Species: []config.BeeSpecies{
+			{Name: "Queen", Amount: 1, Health: 1000, AttackDamage: 10, HitDamage: 1, StunChance: 1, Leader: true},
+		},
+	}
+
+	ge := game.NewGame(cfg)
+
+	// The player gets one attack in, then the queen keeps them stunned
+	attacks, stunned := 0, 0
+	for state := game.Running; state == game.Running; {
+		events, s, err := ge.Step(game.Action{Type: game.ActionHit})
+		if err != nil {
+			t.Fatalf("Step() returned error: %v", err)
+		}
+		state = s
+		for _, event := range events {
+			switch event.Type {
+			case game.PlayerMissed, game.BeeHit:
+				attacks++
+			case game.PlayerStunned:
+				stunned++
+			}
+		}
+	}
+
+	if attacks != 1 || stunned != 2 || ge.TurnsStunned != 2 {
+		t.Errorf("Expected 1 attack then 2 stunned turns, got %d attacks, %d stunned events and %d turns stunned", attacks, stunned, ge.TurnsStunned)
+	}
+}
+
+func TestCollapseRules(t *testing.T) {
+	tests := []struct {
+		rule     string
+		expected []int // bees left after each queen dies
+	}{
+		{"", []int{0}},
+		{config.CollapseFirst, []int{0}},
+		{config.CollapseLast, []int{2, 0}},
+		{config.CollapseNever, []int{2, 1}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.rule, func(t *testing.T) {
+			cfg := &config.Config{
+				PlayerHealth: 100,
+				CollapseRule: tt.rule,
+				RandomSeed:   12345,
+				Species: []config.BeeSpecies{
+					{Name: "Queen", Amount: 2, Health: 10, AttackDamage: 10, HitDamage: 10, Leader: true},
+					{Name: "Worker", Amount: 1, Health: 50, AttackDamage: 5, HitDamage: 10},
+				},
+			}
+
+			ge := game.NewGame(cfg)
+
+			for i, want := range tt.expected {
+				if err := ge.TakeAction(game.Action{Type: game.ActionHit, Target: "queen"}); err != nil {
+					t.Fatalf("TakeAction() returned error: %v", err)
+				}
+				if len(ge.GetHive()) != want {
+					t.Errorf("Expected %d bees after queen %d died, got %d", want, i+1, len(ge.GetHive()))
+				}
+			}
+		})
+	}
+}
+
+func TestWeightedSelection(t *testing.T) {
+	cfg := &config.Config{
+		PlayerHealth: 100000,
+		RandomSeed:   12345,
+		Species: []config.BeeSpecies{
+			{Name: "Queen", Amount: 1, Health: 100000, AttackDamage: 1, HitDamage: 1, TargetWeight: 1, AttackWeight: 1, Leader: true},
+			{Name: "Drone", Amount: 1, Health: 100000, AttackDamage: 1, HitDamage: 1, TargetWeight: 9, AttackWeight: 3},
+		},
+	}
+
+	play := func() []game.Event {
+		ge := game.NewGame(cfg)
+
+		for i := 0; i < 1000; i++ {
+			ge.TakePlayerTurn()
+			ge.TakeBeeTurn()
+		}
+		events := ge.Events()
+		return events
+	}
+
+	events := play()
+	hits, stings := map[game.BeeType]int{}, map[game.BeeType]int{}
+	for _, event := range events {
+		switch event.Type {
+		case game.BeeHit:
+			hits[event.BeeType]++
+		case game.BeeStung:
+			stings[event.BeeType]++
+		}
+	}
+
+	// The drone should take about 90% of the hits and make about 75% of the stings
+	if hits[game.DroneBee] < 850 || hits[game.DroneBee] > 950 {
+		t.Errorf("Expected the drone to take around 900 of 1000 hits, got %v", hits)
+	}
+	if stings[game.DroneBee] < 700 || stings[game.DroneBee] > 800 {
+		t.Errorf("Expected the drone to make around 750 of 1000 stings, got %v", stings)
+	}
+
+	// The same seed picks the same bees
+	if again := play(); !reflect.DeepEqual(events, again) {
+		t.Errorf("Expected identical events for the same seed")
+	}
+}
+
+func TestLoadouts(t *testing.T) {
+	cfg := &config.Config{
+		PlayerHealth: 100,
+		RandomSeed:   12345,
+		Species: []config.BeeSpecies{
+			{Name: "Worker", Amount: 1, Health: 100, AttackDamage: 10, HitDamage: 10},
+		},
+		Loadouts: []config.Loadout{
+			{
+				Name:   "club",
+				Weapon: config.Weapon{Name: "club", Damage: 5, Bonus: map[string]float64{"worker": 2}},
+				Armor:  config.Armor{Name: "padding", Flat: 2, Reduction: 0.5},
+			},
+			{Name: "blindfold", Weapon: config.Weapon{Name: "stick", Accuracy: -1}},
+		},
+	}
+
+	ge := game.NewGame(cfg)
+
+	// The first loadout is used when none is chosen
+	if name := ge.GetPlayer().GetLoadout().Name; name != "club" {
+		t.Errorf("Expected the first loadout by default, got '%s'", name)
+	}
+
+	// The club adds 5 damage and doubles it against workers: (10+5)*2
+	ge.TakePlayerTurn()
+	if hp := ge.GetHive()[0].GetHP(); hp != 70 {
+		t.Errorf("Expected the worker to be left on 70 HP, got %d", hp)
+	}
+
+	// Padding takes 2 off the sting, then half of what's left
+	ge.TakeBeeTurn()
+	if hp := ge.GetPlayer().GetHP(); hp != 96 {
+		t.Errorf("Expected armored sting to leave 96 HP, got %d", hp)
+	}
+	if ge.DamageBlocked != 6 {
+		t.Errorf("Expected armor to block 6 damage, got %d", ge.DamageBlocked)
+	}
+
+	// A weapon with -1 accuracy never hits
+	if err := ge.SetLoadout("BLINDFOLD"); err != nil {
+		t.Fatalf("Expected loadout to be found, got %v", err)
+	}
+	if ge.Config.Loadout != "blindfold" {
+		t.Errorf("Expected the config to record the loadout, got '%s'", ge.Config.Loadout)
+	}
+	for i := 0; i < 20; i++ {
+		ge.TakePlayerTurn()
+	}
+	if hp := ge.GetHive()[0].GetHP(); hp != 70 {
+		t.Errorf("Expected every blindfolded hit to miss, worker has %d HP", hp)
+	}
+
+	if err := ge.SetLoadout("bazooka"); err == nil {
+		t.Errorf("Expected an error for an unknown loadout")
+	}
+}
+
+func TestStep(t *testing.T) {
+	cfg := &config.Config{
+		PlayerHealth:     50,
+		PlayerMissChance: 0.2,
+		RandomSeed:       12345,
+		Species: []config.BeeSpecies{
+			{Name: "Worker", Amount: 3, Health: 30, AttackDamage: 5, HitDamage: 10, MissChance: 0.2},
+		},
+	}
+
+	// An action that can't be taken doesn't play the round
+	ge := game.NewGame(cfg)
+	events, state, err := ge.Step(game.Action{Type: game.ActionHeal})
+	if err == nil || state != game.Running || len(events) != 0 || ge.GetTurn() != 0 {
+		t.Errorf("Expected an error and no round played, got %v, state %v, %d events and turn %d", err, state, len(events), ge.GetTurn())
+	}
+
+	// Each step is a player turn and a bee turn, ending with GameOver
+	var stepped []game.Event
+	for state == game.Running {
+		events, state, err = ge.Step(game.Action{Type: game.ActionHit})
+		if err != nil {
+			t.Fatalf("Step() returned error: %v", err)
+		}
+		stepped = append(stepped, events...)
+	}
+	if last := stepped[len(stepped)-1]; last.Type != game.GameOver || last.State != state || state != ge.State() {
+		t.Errorf("Expected the last event to be GameOver with state %v, got %+v", state, last)
+	}
+	if _, _, err := ge.Step(game.Action{Type: game.ActionHit}); err == nil {
+		t.Errorf("Expected an error stepping a finished game")
+	}
+
+	// Start plays the same game over channels
+	ge = game.NewGame(cfg)
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	go ge.Start(true, ctx)
+
+	var started []game.Event
+	for event := range ge.EventChan {
+		started = append(started, event)
+		if event.Type == game.GameOver {
+			break
+		}
+	}
+	if <-ge.GameStateChan != state || !reflect.DeepEqual(stepped, started) {
+		t.Errorf("Expected Start to play the same game as Step")
+	}
+}
+
+// waitForGoroutines waits for the number of goroutines to drop back to n
+func waitForGoroutines(t *testing.T, n int) {
+	t.Helper()
+	deadline := time.Now().Add(time.Second)
+	for runtime.NumGoroutine() > n {
+		if time.Now().After(deadline) {
+			t.Errorf("Expected goroutines to finish, %d still running, started with %d", runtime.NumGoroutine(), n)
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestStartCancel(t *testing.T) {
+	cfg := &config.Config{
+		PlayerHealth: 100,
+		RandomSeed:   12345,
+		Species: []config.BeeSpecies{
+			{Name: "Worker", Amount: 1, Health: 10, AttackDamage: 1, HitDamage: 1},
+		},
+	}
+
+	tests := []struct {
+		name   string
+		events int // how many events to read before cancelling
+	}{
+		{"Blocked sending an event", 0},
+		{"Blocked waiting for input", 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			goroutines := runtime.NumGoroutine()
+			ge := game.NewGame(cfg)
+			ctx, cancel := context.WithCancel(context.Background())
+			go ge.Start(false, ctx)
+
+			for i := 0; i < tt.events; i++ {
+				<-ge.EventChan
+			}
+			cancel()
+
+			select {
+			case <-ge.Done():
+			case <-time.After(time.Second):
+				t.Fatalf("Expected Start to return after cancelling")
+			}
+			if !errors.Is(ge.Err(), context.Canceled) {
+				t.Errorf("Expected Err() to be context.Canceled, got %v", ge.Err())
+			}
+			waitForGoroutines(t, goroutines)
+		})
+	}
+
+	// A game played to the end finishes without an error
+	ge := game.NewGame(cfg)
+	go ge.Start(true, context.Background())
+	for event := range ge.EventChan {
+		if event.Type == game.GameOver {
+			break
+		}
+	}
+	<-ge.Done()
+	if ge.Err() != nil {
+		t.Errorf("Expected no error for a finished game, got %v", ge.Err())
+	}
+}
+
+func TestView(t *testing.T) {
+	cfg := &config.Config{
+		PlayerHealth:     50,
+		PlayerMissChance: 0.2,
+		RandomSeed:       12345,
+		Species: []config.BeeSpecies{
+			{Name: "Worker", Amount: 3, Health: 30, AttackDamage: 5, HitDamage: 10, MissChance: 0.2},
+		},
+	}
+
+	ge := game.NewGame(cfg)
+	view := ge.View()
+	if view.Turn != 0 || view.State != game.Running || view.Player.HP != 50 || len(view.Hive) != 3 {
+		t.Errorf("Expected a view of the new game, got %+v", view)
+	}
+
+	// A step publishes a new view and leaves the old one as it was
+	if _, _, err := ge.Step(game.Action{Type: game.ActionHit}); err != nil {
+		t.Fatalf("Step() returned error: %v", err)
+	}
+	stepped := ge.View()
+	if view.Turn != 0 || view.Stats.PlayerHits+view.Stats.BeeStings != 0 {
+		t.Errorf("Expected the old view not to change, got %+v", view)
+	}
+	if stepped.Turn != ge.GetTurn() || stepped.Player.HP != ge.GetPlayer().GetHP() ||
+		stepped.Stats.PlayerHits != ge.PlayerHits || stepped.Stats.BeeStings != ge.BeeStings {
+		t.Errorf("Expected the view to match the game after a step, got %+v", stepped)
+	}
+	for i, bee := range ge.GetHive() {
+		if b := stepped.Hive[i]; b.ID != bee.GetID() || b.Type != bee.GetBeeType() || b.HP != bee.GetHP() {
+			t.Errorf("Expected bee %d in the view, got %+v", bee.GetID(), b)
+		}
+	}
+
+	// Views can be read while the game runs, which the race detector checks
+	ge = game.NewGame(cfg)
+	go ge.Start(true, context.Background())
+	for event := range ge.EventChan {
+		if v := ge.View(); v.Player.HP > v.Player.MaxHP || len(v.Hive) > 3 {
+			t.Errorf("Expected a consistent view, got %+v", v)
+		}
+		if event.Type == game.GameOver {
+			break
+		}
+	}
+	<-ge.Done()
+	if v := ge.View(); v.State != ge.State() {
+		t.Errorf("Expected the last view to be in state %v, got %v", ge.State(), v.State)
+	}
+}
+
+func TestScriptedRNG(t *testing.T) {
+	cfg := &config.Config{
+		PlayerHealth:     50,
+		PlayerMissChance: 0.2,
+		Species: []config.BeeSpecies{
+			{Name: "Queen", Amount: 1, Health: 20, AttackDamage: 5, HitDamage: 10, MissChance: 0.2, Leader: true},
+			{Name: "Worker", Amount: 2, Health: 10, AttackDamage: 3, HitDamage: 10, MissChance: 0.2},
+		},
+	}
+
+	// The player hits the queen and worker #3 misses, then a random hit
+	// lands on the queen and the hive collapses
+	rng := game.NewScriptedRNG(
+		game.Chance(0.9), // aimed hit lands
+		game.Index(2),    // worker #3 is the one to sting
+		game.Chance(0.1), // and misses
+		game.Chance(0.9), // random hit lands
+		game.Index(0),    // on the queen
+	)
+	ge := game.NewGame(cfg)
+	ge.SetRNG(rng)
+
+	var types []game.EventType
+	for _, input := range []string{"hit queen", "hit"} {
+		action, err := game.ParseAction(input)
+		if err != nil {
+			t.Fatalf("ParseAction(%q) returned error: %v", input, err)
+		}
+		events, _, err := ge.Step(action)
+		if err != nil {
+			t.Fatalf("Step() returned error: %v", err)
+		}
+		for _, event := range events {
+			types = append(types, event.Type)
+		}
+	}
+
+	expected := []game.EventType{game.BeeHit, game.BeeMissed, game.BeeHit, game.HiveCollapsed, game.GameOver}
+	if !reflect.DeepEqual(types, expected) {
+		t.Errorf("Expected events %v, got %v", expected, types)
+	}
+	if ge.State() != game.PlayerWin || rng.Remaining() != 0 {
+		t.Errorf("Expected the game won with every roll used, got state %v and %d rolls left", ge.State(), rng.Remaining())
+	}
+
+	// The scripted rolls can't be saved, so neither can the game
+	dir := t.TempDir()
+	if err := ge.Save(filepath.Join(dir, "save.json")); err == nil {
+		t.Errorf("Expected an error saving a game with its own RNG")
+	}
+	if err := ge.SaveReplay(filepath.Join(dir, "replay.json")); err == nil {
+		t.Errorf("Expected an error saving a replay of a game with its own RNG")
+	}
+
+	// A script that doesn't match the game panics
+	defer func() {
+		if recover() == nil {
+			t.Errorf("Expected a panic when the game wants a different roll")
+		}
+	}()
+	ge = game.NewGame(cfg)
+	ge.SetRNG(game.NewScriptedRNG(game.Index(1)))
+	ge.Step(game.Action{Type: game.ActionHit})
+}
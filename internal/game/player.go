@@ -1,22 +1,115 @@
 package game
 
 import (
-	"math/rand"
+	"math"
+	"slices"
+	"strings"
+
+	"github.com/lewwolfe/beesinthetrap/internal/config"
 )
 
 type Player struct {
-	hp         int
-	missChance float64
+	hp             int
+	maxHP          int
+	missChance     float64
+	critChance     float64
+	critMultiplier float64
+	healsLeft      int
+	defending      bool
+	dodging        bool
+	items          map[string]int // how many of each item is left, by item name
+	resist         float64
+	resistTurns    int
+	statuses       statuses
+	loadout        config.Loadout
+	class          config.Class
+	cooldown       int // player turns until the class ability can be used again
+	evadeTurns     int // bee turns left of dodging every sting
+}
+
+func (p *Player) Attack(rng RNG) bool {
+	return p.AimedAttack(rng, 0)
+}
+
+// AimedAttack is an attack at a chosen target, which is harder to land. The
+// player's weapon and class make it easier or harder to hit.
+func (p *Player) AimedAttack(rng RNG, missPenalty float64) bool {
+	return rng.Float64() > p.missChance-p.loadout.Weapon.Accuracy-p.class.Accuracy+missPenalty
+}
+
+// HitDamage adds the player's weapon and class to the damage of a hit on a
+// bee type
+func (p *Player) HitDamage(damage int, target BeeType) int {
+	damage += p.loadout.Weapon.Damage + p.class.Damage
+	for species, bonus := range p.loadout.Weapon.Bonus {
+		if strings.EqualFold(species, target.String()) {
+			damage = int(math.Round(float64(damage) * bonus))
+		}
+	}
+	return damage
+}
+
+// Armor takes the player's armor off a sting, returning the damage left and
+// how much was absorbed
+func (p *Player) Armor(damage int) (int, int) {
+	armor := p.loadout.Armor
+	absorbed := min(armor.Flat, damage)
+	absorbed += int(math.Round(float64(damage-absorbed) * armor.Reduction))
+	return damage - absorbed, absorbed
 }
 
-func (p *Player) Attack(rng *rand.Rand) bool {
-	return rng.Float64() > p.missChance
+// RollCrit checks whether a hit is critical and returns the resulting damage
+func (p *Player) RollCrit(rng RNG, damage int) (int, bool) {
+	return rollCrit(rng, damage, p.critChance, p.critMultiplier)
 }
 
 func (p *Player) Sting(damage int) {
 	p.hp -= damage
 }
 
+// Heal restores up to amount HP without going over the player's max, and
+// returns how much was actually restored
+func (p *Player) Heal(amount int) int {
+	healed := min(amount, p.maxHP-p.hp)
+	healed = max(healed, 0)
+	p.hp += healed
+	return healed
+}
+
+// setClass makes the player a class, whose health goes on top of the base
+// health from the config
+func (p *Player) setClass(class config.Class, baseHealth int) {
+	p.class = class
+	p.hp = baseHealth + class.Health
+	p.maxHP = p.hp
+	p.cooldown = 0
+}
+
+// missBonus is how much more likely a bee is to miss the player: always when
+// evading, more so when dodging, and a little for some classes
+func (p *Player) missBonus(dodgeBonus float64) float64 {
+	switch {
+	case p.evadeTurns > 0:
+		return 1
+	case p.dodging:
+		return p.class.Dodge + dodgeBonus
+	}
+	return p.class.Dodge
+}
+
+// endBeeTurn drops any defending or dodging once the bees have had their
+// turn, and wears down any sting resistance or evasion
+func (p *Player) endBeeTurn() {
+	p.defending = false
+	p.dodging = false
+	if p.resistTurns > 0 {
+		p.resistTurns--
+	}
+	if p.evadeTurns > 0 {
+		p.evadeTurns--
+	}
+}
+
 func (p *Player) IsDead() bool {
 	return p.hp <= 0
 }
@@ -24,3 +117,37 @@ func (p *Player) IsDead() bool {
 func (p *Player) GetHP() int {
 	return p.hp
 }
+
+func (p *Player) GetMaxHP() int {
+	return p.maxHP
+}
+
+func (p *Player) GetHealsLeft() int {
+	return p.healsLeft
+}
+
+// GetStatuses returns the status effects the player is under
+func (p *Player) GetStatuses() []Status {
+	return slices.Clone(p.statuses)
+}
+
+// GetLoadout returns the weapon and armor the player is using
+func (p *Player) GetLoadout() config.Loadout {
+	return p.loadout
+}
+
+// GetClass returns the player's class
+func (p *Player) GetClass() config.Class {
+	return p.class
+}
+
+// GetCooldown returns how many turns until the class ability can be used
+// again, 0 when it is ready
+func (p *Player) GetCooldown() int {
+	return p.cooldown
+}
+
+// GetItemCount returns how many of an item the player has left
+func (p *Player) GetItemCount(name string) int {
+	return p.items[name]
+}
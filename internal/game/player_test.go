@@ -32,6 +32,23 @@ func TestPlayerAttack(t *testing.T) {
 	}
 }
 
+// TestPlayerRollCrit tests that player critical hits use the configured multiplier
+func TestPlayerRollCrit(t *testing.T) {
+	rng := rand.New(rand.NewSource(42))
+
+	alwaysCritPlayer := &Player{hp: 20, critChance: 1, critMultiplier: 3}
+	damage, crit := alwaysCritPlayer.RollCrit(rng, 10)
+	if !crit || damage != 30 {
+		t.Errorf("Expected critical hit for 30 damage, got %d (critical %v)", damage, crit)
+	}
+
+	neverCritPlayer := &Player{hp: 20, critMultiplier: 3}
+	damage, crit = neverCritPlayer.RollCrit(rng, 10)
+	if crit || damage != 10 {
+		t.Errorf("Expected normal hit for 10 damage, got %d (critical %v)", damage, crit)
+	}
+}
+
 // TestPlayerSting tests that a player takes the right amount of damage
 func TestPlayerSting(t *testing.T) {
 	player := &Player{
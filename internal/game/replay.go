@@ -0,0 +1,134 @@
+package game
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+)
+
+// Replay records a played game: where it started, the commands the player
+// entered and every event that came out of the engine as a result. Finished
+// is false for a game that was stopped part way, such as by Ctrl-C.
+type Replay struct {
+	Start    *Snapshot `json:"start"`
+	Auto     bool      `json:"auto"`
+	Finished bool      `json:"finished"`
+	Commands []string  `json:"commands"`
+	Events   []Event   `json:"events"`
+}
+
+// errRecordingEnd stops an unfinished replay once its commands run out
+var errRecordingEnd = errors.New("end of the recording")
+
+// Replay returns the recording of the game so far
+func (ge *GameEngine) Replay() *Replay {
+	return &Replay{
+		Start:    ge.replay.Start,
+		Auto:     ge.replay.Auto,
+		Finished: ge.IsGameFinished(),
+		Commands: append([]string(nil), ge.replay.Commands...),
+		Events:   append([]Event(nil), ge.replay.Events...),
+	}
+}
+
+// SaveReplay writes the recording of the game to a JSON file. A game playing
+// with its own RNG can't be replayed, so it returns an error for one.
+func (ge *GameEngine) SaveReplay(path string) error {
+	if ge.customRNG {
+		return errors.New("can't save a replay of a game playing with its own RNG")
+	}
+	data, err := json.MarshalIndent(ge.Replay(), "", "  ")
+	if err != nil {
+		return fmt.Errorf("encoding replay: %w", err)
+	}
+
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("writing replay: %w", err)
+	}
+	return nil
+}
+
+// LoadReplay reads a replay previously written by SaveReplay
+func LoadReplay(path string) (*Replay, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading replay: %w", err)
+	}
+
+	r := new(Replay)
+	if err := json.Unmarshal(data, r); err != nil {
+		return nil, fmt.Errorf("decoding replay %s: %w", path, err)
+	}
+	if r.Start == nil {
+		return nil, fmt.Errorf("replay %s has no starting state", path)
+	}
+	if err := r.Start.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid replay %s: %w", path, err)
+	}
+	return r, nil
+}
+
+// RunReplay plays the recorded commands back through a fresh engine, a round
+// at a time, and checks the game plays out exactly as recorded: every event
+// matches, the game ends with the last event and every command is used. A
+// recording of an unfinished game only has to match as far as it goes.
+func RunReplay(r *Replay) error {
+	ge := NewGameFromSnapshot(r.Start)
+	commands := r.Commands
+	input := func() (string, error) {
+		if err := r.match(ge.replay.Events); err != nil {
+			return "", err
+		}
+		if len(commands) == 0 {
+			if !r.Finished {
+				return "", errRecordingEnd
+			}
+			return "", fmt.Errorf("replay ran out of commands after %d events", len(ge.replay.Events))
+		}
+		cmd := commands[0]
+		commands = commands[1:]
+		return cmd, nil
+	}
+
+	// Every round adds events, so a game that runs on past the recording
+	// stops at the first extra one
+	for !ge.IsGameFinished() {
+		if !r.Finished && len(ge.replay.Events) >= len(r.Events) {
+			break
+		}
+		if _, err := ge.playRound(r.Auto, input); errors.Is(err, errRecordingEnd) {
+			break
+		} else if err != nil {
+			return err
+		}
+		if err := r.match(ge.replay.Events); err != nil {
+			return err
+		}
+	}
+
+	if played := len(ge.replay.Events); played < len(r.Events) {
+		return fmt.Errorf("replay diverged at event %d: expected %+v, got the end of the game", played, r.Events[played])
+	}
+	if len(commands) > 0 {
+		return fmt.Errorf("replay has %d unused commands, starting with %q", len(commands), commands[0])
+	}
+	return nil
+}
+
+// match checks the events played so far against the recording. An
+// unfinished recording says nothing about events past its end.
+func (r *Replay) match(played []Event) error {
+	for i, got := range played {
+		if i >= len(r.Events) {
+			if !r.Finished {
+				return nil
+			}
+			return fmt.Errorf("replay diverged at event %d: expected the end of the recording, got %+v", i, got)
+		}
+		if got != r.Events[i] {
+			return fmt.Errorf("replay diverged at event %d: expected %+v, got %+v", i, r.Events[i], got)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,144 @@
+package game
+
+import (
+	"context"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/lewwolfe/beesinthetrap/internal/config"
+)
+
+// playManualGame plays a game to the end, typing the given commands in a loop
+func playManualGame(ge *GameEngine, commands []string) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	go ge.Start(false, ctx)
+
+	turn := 0
+	for event := range ge.EventChan {
+		switch event.Type {
+		case PlayerPrompt:
+			ge.InputChan <- commands[turn%len(commands)]
+			turn++
+		case GameOver:
+			return
+		}
+	}
+}
+
+// TestReplay tests that a recorded game replays exactly and that tampering is detected
+func TestReplay(t *testing.T) {
+	cfg := &config.Config{
+		PlayerHealth:         30,
+		PlayerMissChance:     0.2,
+		PlayerCritMultiplier: 1,
+		RandomSeed:           99,
+		LogSize:              10,
+		Species: []config.BeeSpecies{
+			{Name: "Worker", Amount: 2, Health: 20, AttackDamage: 5, HitDamage: 10, MissChance: 0.2},
+		},
+	}
+
+	ge := NewGame(cfg)
+	playManualGame(ge, []string{"hit", "dance", "hit"})
+
+	path := filepath.Join(t.TempDir(), "replay.json")
+	if err := ge.SaveReplay(path); err != nil {
+		t.Fatalf("SaveReplay() returned error: %v", err)
+	}
+
+	replay, err := LoadReplay(path)
+	if err != nil {
+		t.Fatalf("LoadReplay() returned error: %v", err)
+	}
+
+	if len(replay.Commands) == 0 || replay.Events[len(replay.Events)-1].Type != GameOver {
+		t.Fatalf("Expected a complete recording, got %d commands and %d events", len(replay.Commands), len(replay.Events))
+	}
+
+	if err := RunReplay(replay); err != nil {
+		t.Errorf("RunReplay() returned error for untouched replay: %v", err)
+	}
+
+	// Change the outcome of the first hit
+	tampered := *replay
+	tampered.Events = slices.Clone(replay.Events)
+	for i, event := range tampered.Events {
+		if event.Type == BeeHit {
+			tampered.Events[i].Damage++
+			break
+		}
+	}
+
+	tests := []struct {
+		name    string
+		replay  Replay
+		wantErr string
+	}{
+		{"Tampered event", tampered, "diverged"},
+		{"Cut short", Replay{Start: replay.Start, Finished: true, Commands: replay.Commands, Events: replay.Events[:len(replay.Events)/2]}, "expected the end of the recording"},
+		{"No events", Replay{Start: replay.Start, Finished: true, Commands: replay.Commands}, "diverged at event 0"},
+		{"Missing commands", Replay{Start: replay.Start, Finished: true, Commands: replay.Commands[:1], Events: replay.Events}, "ran out of commands"},
+		{"Extra commands", Replay{Start: replay.Start, Finished: true, Commands: append(slices.Clone(replay.Commands), "hit"), Events: replay.Events}, "1 unused commands"},
+		{"Unfinished and tampered", Replay{Start: replay.Start, Commands: tampered.Commands, Events: tampered.Events[:len(tampered.Events)/2]}, "diverged"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := RunReplay(&tt.replay)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Expected error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+
+	// A game quit at the third prompt only has to match as far as it went
+	prompts := 0
+	for i, event := range replay.Events {
+		if event.Type == PlayerPrompt {
+			prompts++
+		}
+		if prompts == 3 {
+			quit := Replay{Start: replay.Start, Commands: replay.Commands[:2], Events: replay.Events[:i+1]}
+			if err := RunReplay(&quit); err != nil {
+				t.Errorf("RunReplay() returned error for an unfinished game: %v", err)
+			}
+			break
+		}
+	}
+}
+
+// TestAutoReplay tests that an auto mode game replays without any commands
+func TestAutoReplay(t *testing.T) {
+	ge := NewGame(&config.Config{
+		PlayerHealth: 50,
+		RandomSeed:   7,
+		Species: []config.BeeSpecies{
+			{Name: "Worker", Amount: 3, Health: 20, AttackDamage: 5, HitDamage: 10, MissChance: 0.2},
+		},
+	})
+	go ge.Start(true, context.Background())
+	for event := range ge.EventChan {
+		if event.Type == GameOver {
+			break
+		}
+	}
+	<-ge.Done()
+
+	replay := ge.Replay()
+	if !replay.Finished {
+		t.Errorf("Expected the recording of a finished game to say so")
+	}
+	if err := RunReplay(replay); err != nil {
+		t.Errorf("RunReplay() returned error: %v", err)
+	}
+
+	// An interrupted game plays on past the recording, which is fine
+	interrupted := Replay{Start: replay.Start, Auto: true, Events: replay.Events[:len(replay.Events)/2]}
+	if err := RunReplay(&interrupted); err != nil {
+		t.Errorf("RunReplay() returned error for an interrupted game: %v", err)
+	}
+}
@@ -0,0 +1,176 @@
+package game
+
+import (
+	crand "crypto/rand"
+	"encoding/binary"
+	"fmt"
+	"math"
+	"math/rand"
+	randv2 "math/rand/v2"
+)
+
+// RNG is where the game gets its random numbers from. Float64 returns a
+// number in [0, 1) and Intn one in [0, n). A *rand.Rand is an RNG.
+type RNG interface {
+	Float64() float64
+	Intn(n int) int
+}
+
+// NewMathRNG returns an RNG from math/rand, the kind a game starts with
+func NewMathRNG(seed int64) RNG {
+	return rand.New(rand.NewSource(seed))
+}
+
+// NewPCGRNG returns an RNG from the PCG generator in math/rand/v2
+func NewPCGRNG(seed1, seed2 uint64) RNG {
+	return v2RNG{randv2.New(randv2.NewPCG(seed1, seed2))}
+}
+
+// NewCryptoRNG returns an RNG drawing from crypto/rand, for games that can't
+// be predicted from their seed
+func NewCryptoRNG() RNG {
+	return v2RNG{randv2.New(cryptoSource{})}
+}
+
+// v2RNG adapts a math/rand/v2 generator to RNG
+type v2RNG struct {
+	rng *randv2.Rand
+}
+
+func (r v2RNG) Float64() float64 {
+	return r.rng.Float64()
+}
+
+func (r v2RNG) Intn(n int) int {
+	return r.rng.IntN(n)
+}
+
+// cryptoSource is a math/rand/v2 source backed by crypto/rand
+type cryptoSource struct{}
+
+func (cryptoSource) Uint64() uint64 {
+	var b [8]byte
+	_, _ = crand.Read(b[:]) // never fails as of Go 1.24
+	return binary.LittleEndian.Uint64(b[:])
+}
+
+// Roll is one scripted random number, made with Chance or Index
+type Roll struct {
+	chance  float64
+	index   int
+	isIndex bool
+}
+
+// Chance is a roll for Float64. Attacks land on a high roll, while chances
+// such as crits, poison and stuns happen on a low one.
+func Chance(f float64) Roll {
+	return Roll{chance: f}
+}
+
+// Index is a roll for Intn, such as which of the bees is picked
+func Index(i int) Roll {
+	return Roll{index: i, isIndex: true}
+}
+
+func (r Roll) String() string {
+	if r.isIndex {
+		return fmt.Sprintf("Index(%d)", r.index)
+	}
+	return fmt.Sprintf("Chance(%v)", r.chance)
+}
+
+// ScriptedRNG plays back a list of rolls in order, so a test can set up the
+// exact scenario it wants. It panics if the game asks for a different kind of
+// roll than the next one, or for more rolls than there are, so a script that
+// no longer matches the game fails loudly. Intn(1) only has one answer, so
+// it doesn't use up a roll.
+type ScriptedRNG struct {
+	rolls []Roll
+}
+
+// NewScriptedRNG returns an RNG that plays back the rolls
+func NewScriptedRNG(rolls ...Roll) *ScriptedRNG {
+	return &ScriptedRNG{rolls: rolls}
+}
+
+func (s *ScriptedRNG) Float64() float64 {
+	r := s.next("Float64")
+	if r.isIndex {
+		panic(fmt.Sprintf("scripted RNG: Float64 called but the next roll is %v", r))
+	}
+	return r.chance
+}
+
+func (s *ScriptedRNG) Intn(n int) int {
+	if n == 1 {
+		return 0
+	}
+	r := s.next(fmt.Sprintf("Intn(%d)", n))
+	if !r.isIndex || r.index < 0 || r.index >= n {
+		panic(fmt.Sprintf("scripted RNG: Intn(%d) called but the next roll is %v", n, r))
+	}
+	return r.index
+}
+
+// Remaining returns how many rolls haven't been used yet
+func (s *ScriptedRNG) Remaining() int {
+	return len(s.rolls)
+}
+
+func (s *ScriptedRNG) next(call string) Roll {
+	if len(s.rolls) == 0 {
+		panic(fmt.Sprintf("scripted RNG: %s called but there are no rolls left", call))
+	}
+	r := s.rolls[0]
+	s.rolls = s.rolls[1:]
+	return r
+}
+
+// countingSource wraps a seeded rand source and counts how many values have
+// been drawn from it, so the exact RNG position can be saved and restored.
+type countingSource struct {
+	src   rand.Source64
+	calls uint64
+}
+
+func newCountingSource(seed int64, calls uint64) *countingSource {
+	cs := &countingSource{src: rand.NewSource(seed).(rand.Source64)}
+	// Fast forward to where the saved game left off, never further than a
+	// valid snapshot can have got
+	for cs.calls < min(calls, maxRandCalls) {
+		cs.Int63()
+	}
+	return cs
+}
+
+func (cs *countingSource) Int63() int64 {
+	cs.calls++
+	return cs.src.Int63()
+}
+
+func (cs *countingSource) Uint64() uint64 {
+	cs.calls++
+	return cs.src.Uint64()
+}
+
+func (cs *countingSource) Seed(seed int64) {
+	cs.calls = 0
+	cs.src.Seed(seed)
+}
+
+// rollDamage picks damage between min and max inclusive. A max at or below
+// min means the damage is fixed, and no random number is drawn.
+func rollDamage(rng RNG, min, max int) int {
+	if max <= min {
+		return min
+	}
+	return min + rng.Intn(max-min+1)
+}
+
+// rollCrit multiplies damage with the given chance, reporting whether it did
+func rollCrit(rng RNG, damage int, chance, multiplier float64) (int, bool) {
+	if chance <= 0 || rng.Float64() >= chance {
+		return damage, false
+	}
+	return int(math.Round(float64(damage) * multiplier)), true
+}
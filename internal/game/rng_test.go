@@ -0,0 +1,72 @@
+package game
+
+import (
+	"testing"
+)
+
+func TestRNGs(t *testing.T) {
+	tests := []struct {
+		name string
+		rng  RNG
+	}{
+		{"math/rand", NewMathRNG(42)},
+		{"PCG", NewPCGRNG(1, 2)},
+		{"Crypto", NewCryptoRNG()},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for i := 0; i < 1000; i++ {
+				if f := tt.rng.Float64(); f < 0 || f >= 1 {
+					t.Fatalf("Expected Float64() in [0, 1), got %v", f)
+				}
+				if n := tt.rng.Intn(6); n < 0 || n >= 6 {
+					t.Fatalf("Expected Intn(6) in [0, 6), got %d", n)
+				}
+			}
+		})
+	}
+
+	// The seeded RNGs give the same numbers for the same seed
+	first, second := NewPCGRNG(7, 8), NewPCGRNG(7, 8)
+	for i := 0; i < 10; i++ {
+		if a, b := first.Intn(100), second.Intn(100); a != b {
+			t.Errorf("Expected the same rolls for the same seed, got %d and %d", a, b)
+		}
+	}
+}
+
+func TestScriptedRolls(t *testing.T) {
+	rng := NewScriptedRNG(Chance(0.5), Index(3), Chance(0))
+
+	if f := rng.Float64(); f != 0.5 {
+		t.Errorf("Expected 0.5, got %v", f)
+	}
+	if n := rng.Intn(1); n != 0 || rng.Remaining() != 2 {
+		t.Errorf("Expected Intn(1) to be 0 without using a roll, got %d with %d left", n, rng.Remaining())
+	}
+	if n := rng.Intn(5); n != 3 {
+		t.Errorf("Expected 3, got %d", n)
+	}
+
+	tests := []struct {
+		name string
+		call func()
+	}{
+		{"Index out of range", func() { NewScriptedRNG(Index(3)).Intn(2) }},
+		{"Index for Float64", func() { NewScriptedRNG(Index(0)).Float64() }},
+		{"Chance for Intn", func() { NewScriptedRNG(Chance(0)).Intn(2) }},
+		{"No rolls left", func() { NewScriptedRNG().Float64() }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("Expected a panic")
+				}
+			}()
+			tt.call()
+		})
+	}
+}
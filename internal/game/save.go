@@ -0,0 +1,301 @@
+package game
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"math/rand"
+	"os"
+	"slices"
+
+	"github.com/lewwolfe/beesinthetrap/internal/config"
+)
+
+// Snapshot is everything needed to pick a game back up exactly where it was
+// left, including the position of the random number generator.
+type Snapshot struct {
+	Config     config.Config  `json:"config"`
+	Seed       int64          `json:"seed"`
+	RandCalls  uint64         `json:"rand_calls"`
+	PlayerTurn bool           `json:"player_turn"`
+	NextBeeID  int            `json:"next_bee_id"`
+	Turn       int            `json:"turn"`
+	PlayerHits int            `json:"player_hits"`
+	BeeStings  int            `json:"bee_stings"`
+	Defends    int            `json:"defends"`
+	Dodges     int            `json:"dodges"`
+	Heals      int            `json:"heals"`
+	Blocked    int            `json:"damage_blocked"`
+	ItemsUsed  int            `json:"items_used"`
+	Abilities  int            `json:"abilities_used"`
+	Pacified   int            `json:"pacified_turns"`
+	Poison     int            `json:"poison_damage"`
+	Stunned    int            `json:"turns_stunned"`
+	Enraged    int            `json:"bees_enraged"`
+	Player     PlayerSnapshot `json:"player"`
+	Hive       []BeeSnapshot  `json:"hive"`
+}
+
+type PlayerSnapshot struct {
+	HP             int            `json:"hp"`
+	MaxHP          int            `json:"max_hp"`
+	HealsLeft      int            `json:"heals_left"`
+	MissChance     float64        `json:"miss_chance"`
+	CritChance     float64        `json:"crit_chance"`
+	CritMultiplier float64        `json:"crit_multiplier"`
+	Items          map[string]int `json:"items"`
+	Resist         float64        `json:"resist"`
+	ResistTurns    int            `json:"resist_turns"`
+	Statuses       []Status       `json:"statuses"`
+	Loadout        config.Loadout `json:"loadout"`
+	Class          config.Class   `json:"class"`
+	Cooldown       int            `json:"cooldown"`
+	EvadeTurns     int            `json:"evade_turns"`
+}
+
+type BeeSnapshot struct {
+	ID              int      `json:"id"`
+	Type            BeeType  `json:"type"`
+	HP              int      `json:"hp"`
+	MaxHP           int      `json:"max_hp"`
+	AttackDamage    int      `json:"attack_damage"`
+	AttackDamageMax int      `json:"attack_damage_max"`
+	HitDamage       int      `json:"hit_damage"`
+	HitDamageMax    int      `json:"hit_damage_max"`
+	MissChance      float64  `json:"miss_chance"`
+	CritChance      float64  `json:"crit_chance"`
+	CritMultiplier  float64  `json:"crit_multiplier"`
+	TargetWeight    float64  `json:"target_weight"`
+	AttackWeight    float64  `json:"attack_weight"`
+	PoisonChance    float64  `json:"poison_chance"`
+	StunChance      float64  `json:"stun_chance"`
+	Statuses        []Status `json:"statuses"`
+	Leader          bool     `json:"leader"`
+	Role            string   `json:"role"`
+	HealAmount      int      `json:"heal_amount"`
+	GuardChance     float64  `json:"guard_chance"`
+	Spawns          BeeType  `json:"spawns"`
+	SpawnInterval   int      `json:"spawn_interval"`
+}
+
+// Most random numbers a saved game can have drawn. Restoring one fast
+// forwards the RNG by this many draws, and no real game comes anywhere close.
+const maxRandCalls = 1 << 20
+
+// Validate returns an error if the snapshot can't be carried on from: its
+// config isn't valid, its hive is empty or its RNG position is out of range
+func (s *Snapshot) Validate() error {
+	if err := s.Config.Validate(); err != nil {
+		return err
+	}
+	if len(s.Hive) == 0 {
+		return fmt.Errorf("the hive is empty")
+	}
+	if s.RandCalls > maxRandCalls {
+		return fmt.Errorf("rand_calls must be at most %d, got %d", maxRandCalls, s.RandCalls)
+	}
+	return nil
+}
+
+// Snapshot captures the current state of the game
+func (ge *GameEngine) Snapshot() *Snapshot {
+	s := &Snapshot{
+		Config:     *ge.Config,
+		Seed:       ge.seed,
+		RandCalls:  ge.source.calls,
+		PlayerTurn: ge.playerTurn,
+		NextBeeID:  ge.nextBeeID,
+		Turn:       ge.turn,
+		PlayerHits: ge.PlayerHits,
+		BeeStings:  ge.BeeStings,
+		Defends:    ge.PlayerDefends,
+		Dodges:     ge.PlayerDodges,
+		Heals:      ge.PlayerHeals,
+		Blocked:    ge.DamageBlocked,
+		ItemsUsed:  ge.ItemsUsed,
+		Abilities:  ge.AbilitiesUsed,
+		Pacified:   ge.pacifiedTurns,
+		Poison:     ge.PoisonDamage,
+		Stunned:    ge.TurnsStunned,
+		Enraged:    ge.BeesEnraged,
+		Player: PlayerSnapshot{
+			HP:             ge.player.hp,
+			MaxHP:          ge.player.maxHP,
+			HealsLeft:      ge.player.healsLeft,
+			MissChance:     ge.player.missChance,
+			CritChance:     ge.player.critChance,
+			CritMultiplier: ge.player.critMultiplier,
+			Items:          maps.Clone(ge.player.items),
+			Resist:         ge.player.resist,
+			ResistTurns:    ge.player.resistTurns,
+			Statuses:       slices.Clone(ge.player.statuses),
+			Loadout:        ge.player.loadout,
+			Class:          ge.player.class,
+			Cooldown:       ge.player.cooldown,
+			EvadeTurns:     ge.player.evadeTurns,
+		},
+	}
+
+	for _, bee := range ge.hive {
+		s.Hive = append(s.Hive, BeeSnapshot{
+			ID:              bee.id,
+			Type:            bee.beeType,
+			HP:              bee.hp,
+			MaxHP:           bee.maxHP,
+			AttackDamage:    bee.attackDamage,
+			AttackDamageMax: bee.attackDamageMax,
+			HitDamage:       bee.hitDamage,
+			HitDamageMax:    bee.hitDamageMax,
+			MissChance:      bee.missChance,
+			CritChance:      bee.critChance,
+			CritMultiplier:  bee.critMultiplier,
+			TargetWeight:    bee.targetWeight,
+			AttackWeight:    bee.attackWeight,
+			PoisonChance:    bee.poisonChance,
+			StunChance:      bee.stunChance,
+			Statuses:        slices.Clone(bee.statuses),
+			Leader:          bee.leader,
+			Role:            bee.role,
+			HealAmount:      bee.healAmount,
+			GuardChance:     bee.guardChance,
+			Spawns:          bee.spawns,
+			SpawnInterval:   bee.spawnInterval,
+		})
+	}
+
+	return s
+}
+
+// Restore replaces the current state of the game with the snapshot
+func (ge *GameEngine) Restore(s *Snapshot) {
+	cfg := s.Config
+	ge.Config = &cfg
+	ge.seed = s.Seed
+	ge.source = newCountingSource(s.Seed, s.RandCalls)
+	ge.rng = rand.New(ge.source)
+	ge.customRNG = false
+	ge.playerTurn = s.PlayerTurn
+	ge.nextBeeID = s.NextBeeID
+	ge.turn = s.Turn
+	ge.PlayerHits = s.PlayerHits
+	ge.BeeStings = s.BeeStings
+	ge.PlayerDefends = s.Defends
+	ge.PlayerDodges = s.Dodges
+	ge.PlayerHeals = s.Heals
+	ge.DamageBlocked = s.Blocked
+	ge.ItemsUsed = s.ItemsUsed
+	ge.AbilitiesUsed = s.Abilities
+	ge.pacifiedTurns = s.Pacified
+	ge.PoisonDamage = s.Poison
+	ge.TurnsStunned = s.Stunned
+	ge.BeesEnraged = s.Enraged
+	ge.fled = false
+	ge.player = &Player{
+		hp:             s.Player.HP,
+		maxHP:          s.Player.MaxHP,
+		healsLeft:      s.Player.HealsLeft,
+		missChance:     s.Player.MissChance,
+		critChance:     s.Player.CritChance,
+		critMultiplier: s.Player.CritMultiplier,
+		items:          maps.Clone(s.Player.Items),
+		resist:         s.Player.Resist,
+		resistTurns:    s.Player.ResistTurns,
+		statuses:       slices.Clone(s.Player.Statuses),
+		loadout:        s.Player.Loadout,
+		class:          s.Player.Class,
+		cooldown:       s.Player.Cooldown,
+		evadeTurns:     s.Player.EvadeTurns,
+	}
+	if ge.player.items == nil {
+		ge.player.items = map[string]int{}
+	}
+	// Older saves may not name the class or loadout they were played with
+	ge.Config.Class, ge.Config.Loadout = ge.player.class.Name, ge.player.loadout.Name
+	ge.resumed = true
+
+	ge.hive = make([]*Bee, 0, len(s.Hive))
+	for _, b := range s.Hive {
+		ge.hive = append(ge.hive, &Bee{
+			id:              b.ID,
+			beeType:         b.Type,
+			hp:              b.HP,
+			maxHP:           b.MaxHP,
+			attackDamage:    b.AttackDamage,
+			attackDamageMax: b.AttackDamageMax,
+			hitDamage:       b.HitDamage,
+			hitDamageMax:    b.HitDamageMax,
+			missChance:      b.MissChance,
+			critChance:      b.CritChance,
+			critMultiplier:  b.CritMultiplier,
+			targetWeight:    weightOrDefault(b.TargetWeight),
+			attackWeight:    weightOrDefault(b.AttackWeight),
+			poisonChance:    b.PoisonChance,
+			stunChance:      b.StunChance,
+			statuses:        slices.Clone(b.Statuses),
+			leader:          b.Leader,
+			role:            b.Role,
+			healAmount:      b.HealAmount,
+			guardChance:     b.GuardChance,
+			spawns:          b.Spawns,
+			spawnInterval:   b.SpawnInterval,
+		})
+	}
+
+	// A replay always starts from a known state, so begin a new one
+	ge.replay = &Replay{Start: ge.Snapshot()}
+	ge.publish()
+}
+
+// NewGameFromSnapshot creates a game that carries on from a snapshot
+func NewGameFromSnapshot(s *Snapshot) *GameEngine {
+	cfg := s.Config
+	ge := NewGame(&cfg)
+	ge.Restore(s)
+	return ge
+}
+
+// Save writes the game as of the latest View to a JSON file. Like View it is
+// safe to call from any goroutine while the game runs. A game playing with
+// its own RNG can't be saved, as the snapshot can only carry on the seeded
+// one.
+func (ge *GameEngine) Save(path string) error {
+	if ge.customRNG {
+		return errors.New("can't save a game playing with its own RNG")
+	}
+	ge.mu.Lock()
+	snapshot := ge.snapshot
+	ge.mu.Unlock()
+	// Don't write a save that LoadSnapshot would turn down
+	if snapshot.RandCalls > maxRandCalls {
+		return fmt.Errorf("can't save a game that has drawn more than %d random numbers", maxRandCalls)
+	}
+
+	data, err := json.MarshalIndent(snapshot, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encoding save: %w", err)
+	}
+
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("writing save: %w", err)
+	}
+	return nil
+}
+
+// LoadSnapshot reads a snapshot previously written by Save, returning an
+// error if it can't be carried on from
+func LoadSnapshot(path string) (*Snapshot, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading save: %w", err)
+	}
+
+	s := new(Snapshot)
+	if err := json.Unmarshal(data, s); err != nil {
+		return nil, fmt.Errorf("decoding save %s: %w", path, err)
+	}
+	if err := s.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid save %s: %w", path, err)
+	}
+	return s, nil
+}
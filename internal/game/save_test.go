@@ -0,0 +1,171 @@
+package game
+
+import (
+	"encoding/json"
+	"math"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/lewwolfe/beesinthetrap/internal/config"
+)
+
+// TestSaveAndLoad tests that a restored game carries on exactly like the original
+func TestSaveAndLoad(t *testing.T) {
+	cfg := &config.Config{
+		PlayerHealth:         100,
+		PlayerMissChance:     0.3,
+		PlayerCritMultiplier: 1,
+		RandomSeed:           7,
+		LogSize:              10,
+		Species: []config.BeeSpecies{
+			{Name: "Worker", Amount: 3, Health: 50, AttackDamage: 5, HitDamage: 10, MissChance: 0.3},
+		},
+	}
+
+	original := NewGame(cfg)
+
+	// Save writes the game as of the last round
+	original.Step(Action{Type: ActionHit})
+	original.Step(Action{Type: ActionHit})
+
+	path := filepath.Join(t.TempDir(), "save.json")
+	if err := original.Save(path); err != nil {
+		t.Fatalf("Save() returned error: %v", err)
+	}
+
+	snapshot, err := LoadSnapshot(path)
+	if err != nil {
+		t.Fatalf("LoadSnapshot() returned error: %v", err)
+	}
+	restored := NewGameFromSnapshot(snapshot)
+
+	if !reflect.DeepEqual(original.Snapshot(), restored.Snapshot()) {
+		t.Fatalf("Restored snapshot differs from original")
+	}
+
+	// Both games should roll exactly the same from here on
+	for i := 0; i < 5; i++ {
+		original.Step(Action{Type: ActionHit})
+		restored.Step(Action{Type: ActionHit})
+	}
+
+	if !reflect.DeepEqual(original.Snapshot(), restored.Snapshot()) {
+		t.Errorf("Restored game diverged from original")
+	}
+}
+
+// TestResumeKeepsClass tests that a resumed game keeps the default class and
+// loadout it was started with, and its HP
+func TestResumeKeepsClass(t *testing.T) {
+	cfg := config.Default()
+	cfg.RandomSeed = 3
+
+	ge := NewGame(cfg)
+	if ge.Config.Class != "Beekeeper" || ge.Config.Loadout != "newspaper" || cfg.Class != "" {
+		t.Errorf("Expected the game's own config to name the default class and loadout, got %q and %q", ge.Config.Class, ge.Config.Loadout)
+	}
+	ge.player.Sting(3)
+
+	resumed := NewGameFromSnapshot(ge.Snapshot())
+	if resumed.Config.Class != "Beekeeper" || resumed.Config.Loadout != "newspaper" || !resumed.Resumed() {
+		t.Errorf("Expected a resumed Beekeeper with a newspaper, got %q and %q", resumed.Config.Class, resumed.Config.Loadout)
+	}
+	if err := resumed.SetClass("Scout"); err == nil {
+		t.Errorf("Expected an error changing the class of a resumed game")
+	}
+	if err := resumed.SetLoadout("rake"); err == nil {
+		t.Errorf("Expected an error changing the loadout of a resumed game")
+	}
+	if resumed.player.hp != 117 || resumed.player.class.Name != "Beekeeper" {
+		t.Errorf("Expected a Beekeeper on 117 HP, got %s on %d", resumed.player.class.Name, resumed.player.hp)
+	}
+}
+
+// TestLoadSnapshotMissingFile tests that loading a missing save reports an error
+func TestLoadSnapshotMissingFile(t *testing.T) {
+	if _, err := LoadSnapshot(filepath.Join(t.TempDir(), "missing.json")); err == nil {
+		t.Error("Expected error loading a missing save file")
+	}
+}
+
+// TestLoadSnapshotInvalid tests that saves which can't be carried on from are
+// rejected
+func TestLoadSnapshotInvalid(t *testing.T) {
+	tests := []struct {
+		name   string
+		change func(s *Snapshot)
+	}{
+		{"Invalid config", func(s *Snapshot) { s.Config.PlayerHealth = 0 }},
+		{"Empty hive", func(s *Snapshot) { s.Hive = nil }},
+		{"Too many rand calls", func(s *Snapshot) { s.RandCalls = math.MaxUint64 }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ge := NewGame(config.Default())
+			s := ge.Snapshot()
+			tt.change(s)
+
+			data, _ := json.Marshal(s)
+			path := filepath.Join(t.TempDir(), "save.json")
+			if err := os.WriteFile(path, data, 0644); err != nil {
+				t.Fatal(err)
+			}
+
+			if _, err := LoadSnapshot(path); err == nil {
+				t.Errorf("Expected an error loading the save")
+			}
+		})
+	}
+}
+
+// TestSaveRandCallsBound tests that a game too long to load back isn't saved
+func TestSaveRandCallsBound(t *testing.T) {
+	ge := NewGame(config.Default())
+	ge.source.calls = maxRandCalls + 1
+	ge.publish()
+
+	if err := ge.Save(filepath.Join(t.TempDir(), "save.json")); err == nil {
+		t.Errorf("Expected an error saving a game past %d random numbers", maxRandCalls)
+	}
+}
+
+// TestCountingSourceBound tests that fast forwarding the RNG stops at the most
+// draws a valid save can have
+func TestCountingSourceBound(t *testing.T) {
+	if cs := newCountingSource(1, math.MaxUint64); cs.calls != maxRandCalls {
+		t.Errorf("Expected the RNG to stop at %d draws, got %d", maxRandCalls, cs.calls)
+	}
+}
+
+// TestInputOnlyTakesActions tests that the engine treats save and load as
+// invalid commands rather than touching files
+func TestInputOnlyTakesActions(t *testing.T) {
+	ge := NewGame(config.Default())
+	path := filepath.Join(t.TempDir(), "save.json")
+
+	inputs := []string{"save " + path, "load " + path, "hit"}
+	next := func() (string, error) {
+		input := inputs[0]
+		inputs = inputs[1:]
+		return input, nil
+	}
+	if _, err := ge.promptAction(next); err != nil {
+		t.Fatalf("promptAction() returned error: %v", err)
+	}
+
+	invalid := 0
+	for _, event := range ge.Events() {
+		if event.Type == InvalidCommand {
+			invalid++
+		}
+	}
+	if invalid != 2 {
+		t.Errorf("Expected save and load to be invalid commands, got %d invalid", invalid)
+	}
+	if _, err := os.Stat(path); err == nil {
+		t.Errorf("Expected the engine not to write a save")
+	}
+}
@@ -0,0 +1,124 @@
+package game
+
+import (
+	"slices"
+
+	"github.com/lewwolfe/beesinthetrap/internal/config"
+)
+
+// StatusKind is a type of status effect
+type StatusKind string
+
+// Status effects that can be put on the player or a bee
+const (
+	Poisoned StatusKind = "poisoned" // loses HP every turn
+	Stunned  StatusKind = "stunned"  // misses turns
+	Enraged  StatusKind = "enraged"  // stings harder
+)
+
+// Status is an effect that lasts a number of turns. Applying an effect that is
+// already there adds a stack, up to the effect's max, and restarts it.
+type Status struct {
+	Kind   StatusKind `json:"kind"`
+	Turns  int        `json:"turns"`
+	Stacks int        `json:"stacks"`
+}
+
+// statuses are the status effects on the player or a bee
+type statuses []Status
+
+// apply adds an effect, or stacks it on top of the same effect, and returns
+// the effect as it now stands
+func (s *statuses) apply(kind StatusKind, effect config.StatusEffect) Status {
+	for i := range *s {
+		st := &(*s)[i]
+		if st.Kind == kind {
+			st.Stacks = min(st.Stacks+1, max(effect.MaxStacks, 1))
+			st.Turns = max(st.Turns, effect.Turns)
+			return *st
+		}
+	}
+
+	st := Status{Kind: kind, Turns: effect.Turns, Stacks: 1}
+	*s = append(*s, st)
+	return st
+}
+
+// stacks returns how many stacks of an effect there are, 0 if it isn't there
+func (s statuses) stacks(kind StatusKind) int {
+	for _, st := range s {
+		if st.Kind == kind {
+			return st.Stacks
+		}
+	}
+	return 0
+}
+
+// tick counts down every effect by a turn, removing and returning the ones
+// that have worn off
+func (s *statuses) tick() []StatusKind {
+	var expired []StatusKind
+	for i := range *s {
+		(*s)[i].Turns--
+		if (*s)[i].Turns <= 0 {
+			expired = append(expired, (*s)[i].Kind)
+		}
+	}
+	*s = slices.DeleteFunc(*s, func(st Status) bool {
+		return st.Turns <= 0
+	})
+	return expired
+}
+
+// afflict may poison or stun the player after a bee's sting lands
+func (ge *GameEngine) afflict(bee *Bee) {
+	if bee.poisonChance > 0 && ge.rng.Float64() < bee.poisonChance {
+		st := ge.player.statuses.apply(Poisoned, ge.Config.Poison)
+		ge.emit(Event{Type: StatusApplied, Status: Poisoned, Turns: st.Turns, Stacks: st.Stacks})
+	}
+	if bee.stunChance > 0 && ge.rng.Float64() < bee.stunChance {
+		st := ge.player.statuses.apply(Stunned, ge.Config.Stun)
+		ge.emit(Event{Type: StatusApplied, Status: Stunned, Turns: st.Turns, Stacks: st.Stacks})
+	}
+}
+
+// enrage works up the rest of a dead bee's species
+func (ge *GameEngine) enrage(dead *Bee) {
+	if ge.Config.Enrage.Turns <= 0 {
+		return
+	}
+
+	enraged := 0
+	for _, bee := range ge.hive {
+		if bee != dead && bee.beeType == dead.beeType {
+			bee.statuses.apply(Enraged, ge.Config.Enrage)
+			enraged++
+		}
+	}
+	if enraged > 0 {
+		ge.BeesEnraged += enraged
+		ge.emit(Event{Type: StatusApplied, Status: Enraged, BeeType: dead.beeType, Turns: ge.Config.Enrage.Turns, Count: enraged})
+	}
+}
+
+// endPlayerTurn hurts a poisoned player, wears down their effects and
+// recharges their class ability
+func (ge *GameEngine) endPlayerTurn() {
+	if stacks := ge.player.statuses.stacks(Poisoned); stacks > 0 {
+		damage := stacks * ge.Config.Poison.Damage
+		ge.player.Sting(damage)
+		ge.PoisonDamage += damage
+		ge.emit(Event{Type: StatusDamage, Status: Poisoned, Damage: damage, HP: ge.player.hp})
+	}
+
+	for _, kind := range ge.player.statuses.tick() {
+		ge.emit(Event{Type: StatusExpired, Status: kind})
+	}
+
+	if ge.player.cooldown > 0 {
+		ge.player.cooldown--
+		if ge.player.cooldown == 0 && !ge.player.IsDead() {
+			ge.emit(Event{Type: AbilityReady, Ability: ge.player.class.Ability.Name})
+		}
+	}
+}
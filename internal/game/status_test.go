@@ -0,0 +1,109 @@
+package game
+
+import (
+	"testing"
+
+	"github.com/lewwolfe/beesinthetrap/internal/config"
+)
+
+// TestStatusStacking tests that effects stack up to their max and wear off
+func TestStatusStacking(t *testing.T) {
+	var s statuses
+	poison := config.StatusEffect{Turns: 2, MaxStacks: 2}
+
+	s.apply(Poisoned, poison)
+	s.apply(Poisoned, poison)
+	st := s.apply(Poisoned, poison)
+	if st.Stacks != 2 || st.Turns != 2 {
+		t.Errorf("Expected poison to stack to 2 for 2 turns, got %+v", st)
+	}
+
+	// No max stacks means the effect doesn't stack
+	s.apply(Stunned, config.StatusEffect{Turns: 1})
+	if s.stacks(Stunned) != 1 {
+		t.Errorf("Expected 1 stack of stun, got %d", s.stacks(Stunned))
+	}
+
+	if expired := s.tick(); len(expired) != 1 || expired[0] != Stunned {
+		t.Errorf("Expected stun to wear off first, got %v", expired)
+	}
+	if expired := s.tick(); len(expired) != 1 || expired[0] != Poisoned {
+		t.Errorf("Expected poison to wear off second, got %v", expired)
+	}
+	if len(s) != 0 {
+		t.Errorf("Expected no effects left, got %+v", s)
+	}
+}
+
+// TestStatusEffects tests poison, stun and enrage in a game
+func TestStatusEffects(t *testing.T) {
+	cfg := behaviourConfig()
+	cfg.Poison = config.StatusEffect{Turns: 2, MaxStacks: 3, Damage: 2}
+	cfg.Stun = config.StatusEffect{Turns: 1}
+	cfg.Enrage = config.StatusEffect{Turns: 1, MaxStacks: 1, Bonus: 1}
+	cfg.Species[0].StunChance = 1
+	cfg.Species[2].PoisonChance = 1
+
+	ge := NewGame(cfg)
+
+	queen, _ := ge.GetBee(1)
+	worker, _ := ge.GetBee(3)
+
+	// A worker sting poisons, which hurts at the end of the player's turn
+	ge.sting(worker)
+	ge.endPlayerTurn()
+	if ge.player.hp != 93 || ge.PoisonDamage != 2 {
+		t.Errorf("Expected 5 sting and 2 poison damage, got %d HP and %d poison", ge.player.hp, ge.PoisonDamage)
+	}
+
+	// A queen sting stuns
+	ge.sting(queen)
+	if ge.player.statuses.stacks(Stunned) != 1 {
+		t.Errorf("Expected queen sting to stun, got %+v", ge.player.statuses)
+	}
+
+	// Killing a worker enrages the other one, doubling its sting
+	other, _ := ge.GetBee(4)
+	other.hp = 0
+	ge.checkBeeDead(other)
+	if !worker.HasStatus(Enraged) || ge.BeesEnraged != 1 {
+		t.Errorf("Expected the other worker to be enraged, got %+v and %d enraged", worker.statuses, ge.BeesEnraged)
+	}
+	hp := ge.player.hp
+	ge.sting(worker)
+	if hp-ge.player.hp != 10 {
+		t.Errorf("Expected enraged sting of 10, got %d", hp-ge.player.hp)
+	}
+	events := ge.Events()
+
+	applied := map[StatusKind]int{}
+	for _, e := range events {
+		if e.Type == StatusApplied {
+			applied[e.Status]++
+		}
+	}
+	if applied[Poisoned] != 2 || applied[Stunned] != 1 || applied[Enraged] != 1 {
+		t.Errorf("Expected 2 poisonings, 1 stun and 1 enrage, got %v", applied)
+	}
+}
+
+// TestPoisonAfterWin tests that poison doesn't tick once the last bee is dead
+func TestPoisonAfterWin(t *testing.T) {
+	cfg := &config.Config{
+		PlayerHealth: 2,
+		Poison:       config.StatusEffect{Turns: 3, MaxStacks: 1, Damage: 5},
+		Species: []config.BeeSpecies{
+			{Name: "Worker", Amount: 1, Health: 1, HitDamage: 10},
+		},
+	}
+
+	ge := NewGame(cfg)
+	ge.player.statuses.apply(Poisoned, cfg.Poison)
+	_, state, err := ge.Step(Action{Type: ActionHit})
+	if err != nil {
+		t.Fatalf("Step() returned error: %v", err)
+	}
+	if state != PlayerWin || ge.player.hp != 2 || ge.PoisonDamage != 0 {
+		t.Errorf("Expected a win with no poison damage, got state %v with %d HP and %d poison", state, ge.player.hp, ge.PoisonDamage)
+	}
+}
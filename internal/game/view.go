@@ -0,0 +1,123 @@
+package game
+
+import (
+	"maps"
+	"slices"
+
+	"github.com/lewwolfe/beesinthetrap/internal/config"
+)
+
+// View is a copy of the state of the game for showing to the player. The
+// engine publishes a new one after every round rather than changing it, so
+// it is safe to read from any goroutine while the game runs. Nothing in it
+// should be modified.
+type View struct {
+	Config *config.Config
+	Turn   int
+	State  GameState
+	Player PlayerView
+	Hive   []BeeView
+	Stats  Stats
+}
+
+// PlayerView is the player's part of a View
+type PlayerView struct {
+	HP        int
+	MaxHP     int
+	HealsLeft int
+	Cooldown  int
+	Items     map[string]int
+	Statuses  []Status
+	Class     config.Class
+	Loadout   config.Loadout
+}
+
+// BeeView is a bee in the hive as of a View
+type BeeView struct {
+	ID       int
+	Type     BeeType
+	HP       int
+	MaxHP    int
+	Leader   bool
+	Statuses []Status
+}
+
+// HasStatus reports whether the bee was under a status effect
+func (b BeeView) HasStatus(kind StatusKind) bool {
+	return statuses(b.Statuses).stacks(kind) > 0
+}
+
+// Stats are the counters kept over a game
+type Stats struct {
+	PlayerHits    int
+	BeeStings     int
+	PlayerDefends int
+	PlayerDodges  int
+	PlayerHeals   int
+	DamageBlocked int
+	ItemsUsed     int
+	AbilitiesUsed int
+	PoisonDamage  int
+	TurnsStunned  int
+	BeesEnraged   int
+}
+
+// View returns the latest published state of the game. The engine publishes
+// it when the game is created or loaded and after every Step, so it lags
+// behind calls to TakeAction and TakeBeeTurn until then.
+func (ge *GameEngine) View() *View {
+	ge.mu.Lock()
+	defer ge.mu.Unlock()
+	return ge.view
+}
+
+// publish makes a fresh View of the game for readers on other goroutines,
+// along with the snapshot Save writes
+func (ge *GameEngine) publish() {
+	cfg := *ge.Config
+	v := &View{
+		Config: &cfg,
+		Turn:   ge.turn,
+		State:  ge.State(),
+		Player: PlayerView{
+			HP:        ge.player.hp,
+			MaxHP:     ge.player.maxHP,
+			HealsLeft: ge.player.healsLeft,
+			Cooldown:  ge.player.cooldown,
+			Items:     maps.Clone(ge.player.items),
+			Statuses:  slices.Clone(ge.player.statuses),
+			Class:     ge.player.class,
+			Loadout:   ge.player.loadout,
+		},
+		Hive: make([]BeeView, 0, len(ge.hive)),
+		Stats: Stats{
+			PlayerHits:    ge.PlayerHits,
+			BeeStings:     ge.BeeStings,
+			PlayerDefends: ge.PlayerDefends,
+			PlayerDodges:  ge.PlayerDodges,
+			PlayerHeals:   ge.PlayerHeals,
+			DamageBlocked: ge.DamageBlocked,
+			ItemsUsed:     ge.ItemsUsed,
+			AbilitiesUsed: ge.AbilitiesUsed,
+			PoisonDamage:  ge.PoisonDamage,
+			TurnsStunned:  ge.TurnsStunned,
+			BeesEnraged:   ge.BeesEnraged,
+		},
+	}
+	for _, bee := range ge.hive {
+		v.Hive = append(v.Hive, BeeView{
+			ID:       bee.id,
+			Type:     bee.beeType,
+			HP:       bee.hp,
+			MaxHP:    bee.maxHP,
+			Leader:   bee.leader,
+			Statuses: slices.Clone(bee.statuses),
+		})
+	}
+
+	snapshot := ge.Snapshot()
+	ge.mu.Lock()
+	ge.view = v
+	ge.snapshot = snapshot
+	ge.mu.Unlock()
+}
@@ -0,0 +1,88 @@
+package sim
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"text/tabwriter"
+)
+
+// killers returns the bee types that killed the player in a fixed order
+func (r *Report) killers() []string {
+	names := make([]string, 0, len(r.KilledBy))
+	for name := range r.KilledBy {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func (r *Report) WriteTable(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(tw, "Games\t%d\n", r.Games)
+	fmt.Fprintf(tw, "Wins\t%d\n", r.Wins)
+	fmt.Fprintf(tw, "Win rate\t%.1f%%\n", r.WinRate*100)
+	fmt.Fprintf(tw, "Mean turns\t%.1f\n", r.MeanTurns)
+	fmt.Fprintf(tw, "Turns p50/p90/p99\t%d/%d/%d\n", r.P50Turns, r.P90Turns, r.P99Turns)
+	fmt.Fprintf(tw, "Mean player HP\t%.1f\n", r.MeanPlayerHP)
+	for _, name := range r.killers() {
+		fmt.Fprintf(tw, "Killed by %s\t%d\n", name, r.KilledBy[name])
+	}
+	return tw.Flush()
+}
+
+func (r *Report) WriteJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(r)
+}
+
+func (r *Report) WriteCSV(w io.Writer) error {
+	header := []string{"games", "wins", "win_rate", "mean_turns", "p50_turns", "p90_turns", "p99_turns", "mean_player_hp"}
+	row := []string{
+		strconv.Itoa(r.Games),
+		strconv.Itoa(r.Wins),
+		strconv.FormatFloat(r.WinRate, 'f', 4, 64),
+		strconv.FormatFloat(r.MeanTurns, 'f', 2, 64),
+		strconv.Itoa(r.P50Turns),
+		strconv.Itoa(r.P90Turns),
+		strconv.Itoa(r.P99Turns),
+		strconv.FormatFloat(r.MeanPlayerHP, 'f', 2, 64),
+	}
+	for _, name := range r.killers() {
+		header = append(header, "killed_by_"+name)
+		row = append(row, strconv.Itoa(r.KilledBy[name]))
+	}
+
+	cw := csv.NewWriter(w)
+	cw.Write(header)
+	cw.Write(row)
+	cw.Flush()
+	return cw.Error()
+}
+
+// CheckFormat returns an error if Write doesn't support the format, so it
+// can be checked before any games are played
+func CheckFormat(format string) error {
+	switch format {
+	case "table", "json", "csv":
+		return nil
+	}
+	return fmt.Errorf("unknown output format %q, expected table, json or csv", format)
+}
+
+// Write outputs the report in the given format: table, json or csv
+func (r *Report) Write(w io.Writer, format string) error {
+	switch format {
+	case "table":
+		return r.WriteTable(w)
+	case "json":
+		return r.WriteJSON(w)
+	case "csv":
+		return r.WriteCSV(w)
+	}
+	return CheckFormat(format)
+}
@@ -0,0 +1,156 @@
+package sim
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/lewwolfe/beesinthetrap/internal/config"
+	"github.com/lewwolfe/beesinthetrap/internal/game"
+)
+
+type Options struct {
+	Games   int
+	Seed    int64
+	Workers int
+}
+
+// Result is the outcome of a single simulated game
+type Result struct {
+	Seed     int64
+	State    game.GameState
+	Turns    int
+	PlayerHP int
+	KilledBy game.BeeType
+}
+
+// Report aggregates the results of a batch of simulated games
+type Report struct {
+	Games        int            `json:"games"`
+	Wins         int            `json:"wins"`
+	WinRate      float64        `json:"win_rate"`
+	MeanTurns    float64        `json:"mean_turns"`
+	P50Turns     int            `json:"p50_turns"`
+	P90Turns     int            `json:"p90_turns"`
+	P99Turns     int            `json:"p99_turns"`
+	MeanPlayerHP float64        `json:"mean_player_hp"`
+	KilledBy     map[string]int `json:"killed_by"`
+}
+
+// Run plays opts.Games auto mode games in parallel, each with its own seed
+// counting up from opts.Seed, and reports on how they went. It returns an
+// error if any game couldn't be played to the end.
+func Run(cfg *config.Config, opts Options) (*Report, error) {
+	if opts.Games < 0 {
+		return nil, fmt.Errorf("number of games can't be negative, got %d", opts.Games)
+	}
+	if opts.Workers < 1 {
+		opts.Workers = 1
+	}
+
+	results := make([]Result, opts.Games)
+	errs := make([]error, opts.Games)
+	games := make(chan int)
+
+	var wg sync.WaitGroup
+	for w := 0; w < opts.Workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range games {
+				gameCfg := *cfg
+				gameCfg.RandomSeed = opts.Seed + int64(i)
+				results[i], errs[i] = playGame(&gameCfg)
+			}
+		}()
+	}
+
+	for i := 0; i < opts.Games; i++ {
+		games <- i
+	}
+	close(games)
+	wg.Wait()
+
+	// Report the first game to fail so the error is the same every run
+	for _, err := range errs {
+		if err != nil {
+			return nil, err
+		}
+	}
+	return summarise(results), nil
+}
+
+// playGame runs a single game in auto mode without any UI
+func playGame(cfg *config.Config) (Result, error) {
+	ge := game.NewGame(cfg)
+	result := Result{Seed: cfg.RandomSeed}
+
+	for {
+		events, state, err := ge.Step(ge.AutoAction())
+		if err != nil {
+			return Result{}, fmt.Errorf("game with seed %d: %w", cfg.RandomSeed, err)
+		}
+
+		for _, event := range events {
+			switch event.Type {
+			case game.BeeStung:
+				result.KilledBy = event.BeeType
+			case game.StatusDamage:
+				// Poison gets the blame if it finishes the player off
+				result.KilledBy = game.BeeType(event.Status)
+			}
+		}
+
+		if state != game.Running {
+			// A bee turn counts once however many bees swarmed, and every bee
+			// turn follows a player turn. The player has had one more if the
+			// game ended on their turn.
+			result.Turns = 2 * ge.GetTurn()
+			if !ge.IsPlayerTurn() {
+				result.Turns++
+			}
+			result.State = state
+			result.PlayerHP = max(ge.GetPlayer().GetHP(), 0)
+			return result, nil
+		}
+	}
+}
+
+func summarise(results []Result) *Report {
+	r := &Report{Games: len(results), KilledBy: map[string]int{}}
+	if len(results) == 0 {
+		return r
+	}
+
+	turns := make([]int, 0, len(results))
+	totalTurns, totalHP := 0, 0
+	for _, res := range results {
+		if res.State == game.PlayerWin {
+			r.Wins++
+		} else {
+			r.KilledBy[res.KilledBy.String()]++
+		}
+		turns = append(turns, res.Turns)
+		totalTurns += res.Turns
+		totalHP += res.PlayerHP
+	}
+	sort.Ints(turns)
+
+	r.WinRate = float64(r.Wins) / float64(r.Games)
+	r.MeanTurns = float64(totalTurns) / float64(r.Games)
+	r.MeanPlayerHP = float64(totalHP) / float64(r.Games)
+	r.P50Turns = percentile(turns, 50)
+	r.P90Turns = percentile(turns, 90)
+	r.P99Turns = percentile(turns, 99)
+
+	return r
+}
+
+// percentile uses the nearest-rank method on already sorted values
+func percentile(sorted []int, p int) int {
+	rank := (p*len(sorted) + 99) / 100
+	if rank < 1 {
+		rank = 1
+	}
+	return sorted[rank-1]
+}
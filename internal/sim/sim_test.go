@@ -0,0 +1,103 @@
+package sim
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/lewwolfe/beesinthetrap/internal/config"
+)
+
+func testConfig() *config.Config {
+	return &config.Config{
+		PlayerHealth:     40,
+		PlayerMissChance: 0.1,
+		Species: []config.BeeSpecies{
+			{Name: "Worker", Amount: 3, Health: 30, AttackDamage: 5, HitDamage: 10, MissChance: 0.2},
+			{Name: "Queen", Amount: 1, Health: 40, AttackDamage: 10, HitDamage: 10, MissChance: 0.2, Leader: true},
+		},
+	}
+}
+
+func TestRun(t *testing.T) {
+	report, err := Run(testConfig(), Options{Games: 200, Seed: 1, Workers: 4})
+	if err != nil {
+		t.Fatalf("Run() returned error: %v", err)
+	}
+
+	if report.Games != 200 {
+		t.Fatalf("Expected 200 games, got %d", report.Games)
+	}
+
+	losses := 0
+	for _, count := range report.KilledBy {
+		losses += count
+	}
+	if report.Wins+losses != report.Games {
+		t.Errorf("Wins (%d) and losses (%d) should add up to games played (%d)", report.Wins, losses, report.Games)
+	}
+
+	if report.P50Turns > report.P90Turns || report.P90Turns > report.P99Turns {
+		t.Errorf("Percentiles out of order: %d/%d/%d", report.P50Turns, report.P90Turns, report.P99Turns)
+	}
+
+	// The same seeds should give the same report however many workers run them
+	again, _ := Run(testConfig(), Options{Games: 200, Seed: 1, Workers: 1})
+	if !reflect.DeepEqual(report, again) {
+		t.Errorf("Expected identical reports for identical seeds, got %+v and %+v", report, again)
+	}
+}
+
+func TestRunNegativeGames(t *testing.T) {
+	if _, err := Run(testConfig(), Options{Games: -1, Seed: 1}); err == nil {
+		t.Error("Expected error for a negative number of games")
+	}
+}
+
+func TestPercentile(t *testing.T) {
+	sorted := []int{1, 2, 3, 4, 5, 6, 7, 8, 9, 10}
+	tests := []struct {
+		p        int
+		expected int
+	}{
+		{50, 5},
+		{90, 9},
+		{99, 10},
+	}
+
+	for _, tt := range tests {
+		if got := percentile(sorted, tt.p); got != tt.expected {
+			t.Errorf("percentile(%d) = %d, want %d", tt.p, got, tt.expected)
+		}
+	}
+}
+
+func TestReportWrite(t *testing.T) {
+	report := &Report{Games: 2, Wins: 1, WinRate: 0.5, KilledBy: map[string]int{"Queen": 1}}
+
+	var buf bytes.Buffer
+	if err := report.Write(&buf, "json"); err != nil {
+		t.Fatalf("Write(json) returned error: %v", err)
+	}
+	decoded := new(Report)
+	if err := json.Unmarshal(buf.Bytes(), decoded); err != nil || decoded.KilledBy["Queen"] != 1 {
+		t.Errorf("Expected JSON report to round trip, got %+v (err %v)", decoded, err)
+	}
+
+	buf.Reset()
+	if err := report.Write(&buf, "csv"); err != nil {
+		t.Fatalf("Write(csv) returned error: %v", err)
+	}
+	if !strings.HasPrefix(buf.String(), "games,wins,win_rate") || !strings.Contains(buf.String(), "killed_by_Queen") {
+		t.Errorf("Unexpected CSV output: %s", buf.String())
+	}
+
+	if err := report.Write(&buf, "xml"); err == nil {
+		t.Error("Expected error for unknown format")
+	}
+	if err := CheckFormat("xml"); err == nil || CheckFormat("csv") != nil {
+		t.Errorf("Expected CheckFormat to only reject unknown formats, got %v", err)
+	}
+}
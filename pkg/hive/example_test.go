@@ -0,0 +1,134 @@
+package hive_test
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"github.com/lewwolfe/beesinthetrap/pkg/hive"
+)
+
+// smallHive is a quick game against a queen and two workers
+func smallHive() *hive.Config {
+	cfg := hive.DefaultConfig()
+	cfg.Species = []hive.BeeSpecies{
+		{Name: "Queen", Amount: 1, Health: 40, AttackDamage: 8, HitDamage: 10, Leader: true},
+		{Name: "Worker", Amount: 2, Health: 20, AttackDamage: 4, HitDamage: 10},
+	}
+	return cfg
+}
+
+// Play a whole game a round at a time, letting the game pick each action
+func Example() {
+	g, err := hive.New(hive.WithConfig(smallHive()), hive.WithSeed(42))
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	state := hive.Running
+	for state == hive.Running {
+		if _, state, err = g.Step(g.AutoAction()); err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	view := g.View()
+	fmt.Printf("Game over after %d turns with %d/%d HP left\n", view.Turn, view.Player.HP, view.Player.MaxHP)
+	fmt.Printf("Hits: %d, stings: %d\n", view.Stats.PlayerHits, view.Stats.BeeStings)
+	fmt.Println("Won:", view.State == hive.PlayerWin)
+	// Output:
+	// Game over after 9 turns with 86/120 HP left
+	// Hits: 8, stings: 5
+	// Won: true
+}
+
+func ExampleGame_Step() {
+	g, err := hive.New(hive.WithConfig(smallHive()), hive.WithSeed(42))
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	action, err := hive.ParseAction("hit queen")
+	if err != nil {
+		log.Fatal(err)
+	}
+	events, state, err := g.Step(action)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	for _, event := range events {
+		fmt.Println(event.Type, event.BeeType, event.Damage)
+	}
+	fmt.Println("Running:", state == hive.Running)
+	// Output:
+	// BeeHit Queen 10
+	// BeeStung Worker 4
+	// Running: true
+}
+
+func ExampleGame_View() {
+	g, err := hive.New(hive.WithConfig(smallHive()))
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	for _, bee := range g.View().Hive {
+		fmt.Printf("#%d %s %d/%d HP\n", bee.ID, bee.Type, bee.HP, bee.MaxHP)
+	}
+	// Output:
+	// #1 Queen 40/40 HP
+	// #2 Worker 20/20 HP
+	// #3 Worker 20/20 HP
+}
+
+// Run a game over channels, as a UI would, in auto mode
+func ExampleGame_Run() {
+	g, err := hive.New(hive.WithConfig(smallHive()), hive.WithSeed(42))
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	go g.Run(context.Background(), true)
+
+	stings := 0
+	for event := range g.Events() {
+		if event.Type == hive.BeeStung {
+			stings++
+		}
+		if event.Type == hive.GameOver {
+			break
+		}
+	}
+	fmt.Println("Stung", stings, "times")
+	fmt.Println("Won:", <-g.Result() == hive.PlayerWin)
+	// Output:
+	// Stung 5 times
+	// Won: true
+}
+
+// Script every roll of a round to test an exact scenario
+func ExampleNewScriptedRNG() {
+	rng := hive.NewScriptedRNG(
+		hive.Chance(0.99), // the player's hit lands
+		hive.Index(1),     // the second bee in the hive stings back
+		hive.Chance(0.5),  // and lands its sting too
+	)
+	g, err := hive.New(hive.WithConfig(smallHive()), hive.WithRNG(rng))
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	events, _, err := g.Step(hive.Action{Type: hive.ActionHit, Target: "queen"})
+	if err != nil {
+		log.Fatal(err)
+	}
+	for _, event := range events {
+		fmt.Println(event.Type, event.BeeType, event.BeeID, event.Damage)
+	}
+	fmt.Println("Rolls left:", rng.Remaining())
+	// Output:
+	// BeeHit Queen 1 10
+	// BeeStung Worker 2 4
+	// Rolls left: 0
+}
@@ -0,0 +1,178 @@
+package hive
+
+import (
+	"context"
+
+	"github.com/lewwolfe/beesinthetrap/internal/game"
+)
+
+// Game is a game of Bees in the Trap. It can be played a round at a time with
+// Step, or over channels with Run, but not both. View is safe to call from
+// any goroutine while it is played.
+type Game struct {
+	engine *game.GameEngine
+}
+
+// Option changes the settings a game is created with
+type Option func(s *settings)
+
+type settings struct {
+	cfg     *Config
+	seed    int64
+	class   string
+	loadout string
+	rng     RNG
+}
+
+// WithConfig plays with the given settings instead of the default ones. The
+// config isn't changed by the game or the other options.
+func WithConfig(cfg *Config) Option {
+	return func(s *settings) {
+		s.cfg = cfg
+	}
+}
+
+// WithSeed makes the game play out the same every time for the same seed
+func WithSeed(seed int64) Option {
+	return func(s *settings) {
+		s.seed = seed
+	}
+}
+
+// WithClass plays as one of the classes in the config
+func WithClass(name string) Option {
+	return func(s *settings) {
+		s.class = name
+	}
+}
+
+// WithLoadout plays with one of the loadouts in the config
+func WithLoadout(name string) Option {
+	return func(s *settings) {
+		s.loadout = name
+	}
+}
+
+// WithRNG has the game draw its random numbers from rng instead of from the
+// seed. A game with its own RNG can't be saved or replayed, so Save and
+// SaveReplay return an error for it.
+func WithRNG(rng RNG) Option {
+	return func(s *settings) {
+		s.rng = rng
+	}
+}
+
+// New creates a game, returning an error if its settings aren't valid
+func New(opts ...Option) (*Game, error) {
+	s := settings{cfg: DefaultConfig()}
+	for _, opt := range opts {
+		opt(&s)
+	}
+
+	cfg := *s.cfg
+	if s.seed != 0 {
+		cfg.RandomSeed = s.seed
+	}
+	if s.class != "" {
+		cfg.Class = s.class
+	}
+	if s.loadout != "" {
+		cfg.Loadout = s.loadout
+	}
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+
+	engine := game.NewGame(&cfg)
+	if s.rng != nil {
+		engine.SetRNG(s.rng)
+	}
+	return &Game{engine: engine}, nil
+}
+
+// Load carries on a game saved with Save, returning an error if the save
+// isn't valid
+func Load(path string) (*Game, error) {
+	snapshot, err := game.LoadSnapshot(path)
+	if err != nil {
+		return nil, err
+	}
+	return &Game{engine: game.NewGameFromSnapshot(snapshot)}, nil
+}
+
+// Save writes the game as of the latest View to a JSON file that Load can
+// carry on from. It is safe to call while Run is playing the game.
+func (g *Game) Save(path string) error {
+	return g.engine.Save(path)
+}
+
+// SaveReplay writes a recording of the game so far to a JSON file, which can
+// be checked with "beesinthetrap replay <file>"
+func (g *Game) SaveReplay(path string) error {
+	return g.engine.SaveReplay(path)
+}
+
+// SetClass changes the player's class before the game starts. A resumed game
+// keeps the class it was saved with.
+func (g *Game) SetClass(name string) error {
+	return g.engine.SetClass(name)
+}
+
+// SetLoadout changes the player's weapon and armor before the game starts. A
+// resumed game keeps the loadout it was saved with.
+func (g *Game) SetLoadout(name string) error {
+	return g.engine.SetLoadout(name)
+}
+
+// Resumed reports whether the game was carried on from a save with Load
+func (g *Game) Resumed() bool {
+	return g.engine.Resumed()
+}
+
+// Step plays a round: the player's action and then the bees' turn. It
+// returns what happened and the state of the game after the round. If the
+// action can't be taken the round isn't played and an error is returned,
+// as it is once the game is over.
+func (g *Game) Step(action Action) ([]Event, State, error) {
+	return g.engine.Step(action)
+}
+
+// AutoAction is what the player does in auto mode: their class ability when
+// it is ready, otherwise a hit
+func (g *Game) AutoAction() Action {
+	return g.engine.AutoAction()
+}
+
+// View returns the state of the game as of the last round
+func (g *Game) View() *View {
+	return g.engine.View()
+}
+
+// Run plays the game until it is over or ctx is cancelled, for a UI running
+// in other goroutines. In auto mode it plays itself, otherwise it waits for
+// the player's commands on Input. What happens is sent on Events, which has
+// to be read for the game to go on, and the final state on Result. Run
+// returns nil once the game is over, or the context's error if it was
+// cancelled. It is only meant to be called once.
+func (g *Game) Run(ctx context.Context, auto bool) error {
+	g.engine.Start(auto, ctx)
+	return g.engine.Err()
+}
+
+// Events returns the channel Run sends what happens on
+func (g *Game) Events() <-chan Event {
+	return g.engine.EventChan
+}
+
+// Input returns the channel Run reads the player's actions from, such as
+// "hit queen". Only game actions are read, so it is safe to pass on input
+// from remote players.
+func (g *Game) Input() chan<- string {
+	return g.engine.InputChan
+}
+
+// Result returns the channel Run sends the final state on once the game is
+// over
+func (g *Game) Result() <-chan State {
+	return g.engine.GameStateChan
+}
@@ -0,0 +1,102 @@
+package hive_test
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/lewwolfe/beesinthetrap/pkg/hive"
+)
+
+func TestNew(t *testing.T) {
+	tests := []struct {
+		name    string
+		opts    []hive.Option
+		class   string
+		loadout string
+		wantErr bool
+	}{
+		{"Defaults", nil, "Beekeeper", "newspaper", false},
+		{"Class and loadout", []hive.Option{hive.WithClass("Scout"), hive.WithLoadout("swatter")}, "Scout", "swatter", false},
+		{"Unknown class", []hive.Option{hive.WithClass("Wizard")}, "", "", true},
+		{"Unknown loadout", []hive.Option{hive.WithLoadout("Bazooka")}, "", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g, err := hive.New(tt.opts...)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Expected error %v, got %v", tt.wantErr, err)
+			}
+			if err != nil {
+				return
+			}
+
+			player := g.View().Player
+			if player.Class.Name != tt.class || player.Loadout.Name != tt.loadout {
+				t.Errorf("Expected %s with %s, got %s with %s", tt.class, tt.loadout, player.Class.Name, player.Loadout.Name)
+			}
+		})
+	}
+}
+
+func TestNewKeepsConfig(t *testing.T) {
+	cfg := hive.DefaultConfig()
+	if _, err := hive.New(hive.WithConfig(cfg), hive.WithSeed(7), hive.WithClass("Scout")); err != nil {
+		t.Fatalf("New() returned error: %v", err)
+	}
+	if !reflect.DeepEqual(cfg, hive.DefaultConfig()) {
+		t.Errorf("Expected the options not to change the config passed in")
+	}
+}
+
+// play steps through a game with the auto actions and returns every event
+func play(t *testing.T, g *hive.Game) []hive.Event {
+	t.Helper()
+	var events []hive.Event
+	for g.View().State == hive.Running {
+		stepped, _, err := g.Step(g.AutoAction())
+		if err != nil {
+			t.Fatalf("Step() returned error: %v", err)
+		}
+		events = append(events, stepped...)
+	}
+	return events
+}
+
+func TestWithSeed(t *testing.T) {
+	first, _ := hive.New(hive.WithSeed(99))
+	second, _ := hive.New(hive.WithSeed(99))
+	if !reflect.DeepEqual(play(t, first), play(t, second)) {
+		t.Errorf("Expected games with the same seed to play out the same")
+	}
+}
+
+func TestSaveAndLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "save.json")
+	g, _ := hive.New(hive.WithSeed(5))
+	if _, _, err := g.Step(hive.Action{Type: hive.ActionHit}); err != nil {
+		t.Fatalf("Step() returned error: %v", err)
+	}
+	if err := g.Save(path); err != nil {
+		t.Fatalf("Save() returned error: %v", err)
+	}
+
+	loaded, err := hive.Load(path)
+	if err != nil {
+		t.Fatalf("Load() returned error: %v", err)
+	}
+	if !reflect.DeepEqual(loaded.View(), g.View()) {
+		t.Errorf("Expected the loaded game to match the saved one")
+	}
+	if !loaded.Resumed() || g.Resumed() || loaded.SetClass("Scout") == nil {
+		t.Errorf("Expected only the loaded game to be resumed, keeping its class")
+	}
+	if !reflect.DeepEqual(play(t, loaded), play(t, g)) {
+		t.Errorf("Expected the loaded game to play out like the saved one")
+	}
+
+	if _, err := hive.Load(filepath.Join(t.TempDir(), "missing.json")); err == nil {
+		t.Errorf("Expected an error loading a missing save")
+	}
+}
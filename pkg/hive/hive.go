@@ -0,0 +1,229 @@
+// Package hive runs games of Bees in the Trap, so they can be played from
+// other programs as well as the command line game.
+package hive
+
+import (
+	"github.com/lewwolfe/beesinthetrap/internal/config"
+	"github.com/lewwolfe/beesinthetrap/internal/game"
+)
+
+// Settings for a game, see config.example.json for what each one does
+type (
+	Config       = config.Config
+	BeeSpecies   = config.BeeSpecies
+	StatusEffect = config.StatusEffect
+	Item         = config.Item
+	Loadout      = config.Loadout
+	Weapon       = config.Weapon
+	Armor        = config.Armor
+	Class        = config.Class
+	Ability      = config.Ability
+)
+
+// Bee roles
+const (
+	RoleStinger = config.RoleStinger
+	RoleHealer  = config.RoleHealer
+	RoleGuard   = config.RoleGuard
+)
+
+// Hive collapse rules
+const (
+	CollapseFirst = config.CollapseFirst
+	CollapseLast  = config.CollapseLast
+	CollapseNever = config.CollapseNever
+)
+
+// Swarm modes
+const (
+	SwarmFixed        = config.SwarmFixed
+	SwarmProportional = config.SwarmProportional
+	SwarmPerType      = config.SwarmPerType
+)
+
+// Item and ability effects
+const (
+	EffectPacify     = config.EffectPacify
+	EffectAreaDamage = config.EffectAreaDamage
+	EffectHeal       = config.EffectHeal
+	EffectEvade      = config.EffectEvade
+)
+
+// DefaultConfig returns the settings of the standard game
+func DefaultConfig() *Config {
+	return config.Default()
+}
+
+// ProfileConfig returns the settings of a difficulty profile, such as "easy"
+func ProfileConfig(name string) (*Config, error) {
+	return config.ProfileConfig(name)
+}
+
+// Profiles returns the names of the difficulty profiles
+func Profiles() []string {
+	return config.Profiles()
+}
+
+// Event describes a single thing that happened in a game. Only the fields
+// relevant to its Type are set, and each field means the same thing for
+// every type that sets it:
+//
+//   - BeeType and BeeID: the bee that acted or was acted on, for BeeHit,
+//     BeeKilled, HiveCollapsed, BeeStung, BeeMissed, BeeSpawned, BeeHealed
+//     and BeeGuarded. For an Enraged StatusApplied, BeeType is the species
+//     that was enraged.
+//   - OtherType and OtherID: the bee healed by BeeHealed, or guarded by
+//     BeeGuarded
+//   - Damage: damage dealt, to a bee for BeeHit, and to the player for
+//     BeeStung, SwarmAttacked and StatusDamage
+//   - Blocked: damage the player's armor, defending or resistance took off
+//     a BeeStung
+//   - Healed: HP restored, by PlayerHealed and BeeHealed
+//   - HP: what is left of whoever took the damage or was healed, or the HP
+//     of the bee hatched by BeeSpawned
+//   - Critical: whether a BeeHit or BeeStung was a critical
+//   - Count: how many bees, stung in a SwarmAttacked or enraged by an
+//     Enraged StatusApplied
+//   - Status: the effect of a StatusApplied, StatusDamage or StatusExpired
+//   - Stacks: how many stacks of Poisoned or Stunned the player has after a
+//     StatusApplied
+//   - Turns: how long an effect lasts, for StatusApplied, ItemUsed,
+//     AbilityUsed and BeesPacified
+//   - Item: the item used by ItemUsed
+//   - Ability: the ability used by AbilityUsed or ready again for AbilityReady
+//   - Input: what the player typed, for InvalidCommand and CommandFailed.
+//     For an action that only fails once it is played, it is the target.
+//   - Path: the file written by GameSaved or read by GameLoaded
+//   - State: how the game ended, for GameOver
+//   - Err: why an InvalidCommand or CommandFailed failed
+type Event = game.Event
+
+// What a game is played with and reports back
+type (
+	Action     = game.Action
+	ActionType = game.ActionType
+	EventType  = game.EventType
+	State      = game.GameState
+	BeeType    = game.BeeType
+	Status     = game.Status
+	StatusKind = game.StatusKind
+	View       = game.View
+	PlayerView = game.PlayerView
+	BeeView    = game.BeeView
+	Stats      = game.Stats
+)
+
+// Actions the player can take on their turn
+const (
+	ActionHit     = game.ActionHit
+	ActionDefend  = game.ActionDefend
+	ActionDodge   = game.ActionDodge
+	ActionHeal    = game.ActionHeal
+	ActionFlee    = game.ActionFlee
+	ActionUse     = game.ActionUse
+	ActionSpecial = game.ActionSpecial
+)
+
+// States of a game
+const (
+	Running    = game.Running
+	PlayerWin  = game.PlayerWin
+	PlayerLose = game.PlayerLose
+	PlayerFled = game.PlayerFled
+)
+
+// Names of the species in the default hive
+const (
+	QueenBee  = game.QueenBee
+	WorkerBee = game.WorkerBee
+	DroneBee  = game.DroneBee
+)
+
+// Status effects that can be put on the player or a bee
+const (
+	Poisoned = game.Poisoned
+	Stunned  = game.Stunned
+	Enraged  = game.Enraged
+)
+
+// Everything a game reports back, see Event for the fields each one sets
+const (
+	PlayerPrompt    = game.PlayerPrompt
+	InvalidCommand  = game.InvalidCommand
+	PlayerMissed    = game.PlayerMissed
+	BeeHit          = game.BeeHit
+	BeeKilled       = game.BeeKilled
+	HiveCollapsed   = game.HiveCollapsed
+	BeeStung        = game.BeeStung
+	BeeMissed       = game.BeeMissed
+	GameOver        = game.GameOver
+	PlayerDefended  = game.PlayerDefended
+	PlayerDodged    = game.PlayerDodged
+	PlayerHealed    = game.PlayerHealed
+	PlayerRetreated = game.PlayerRetreated
+	ItemUsed        = game.ItemUsed
+	BeesPacified    = game.BeesPacified
+	BeeSpawned      = game.BeeSpawned
+	BeeHealed       = game.BeeHealed
+	BeeGuarded      = game.BeeGuarded
+	SwarmAttacked   = game.SwarmAttacked
+	StatusApplied   = game.StatusApplied
+	StatusDamage    = game.StatusDamage
+	StatusExpired   = game.StatusExpired
+	PlayerStunned   = game.PlayerStunned
+	AbilityUsed     = game.AbilityUsed
+	AbilityReady    = game.AbilityReady
+)
+
+// Events a client can log for its own saving and loading, the game never
+// sends them itself
+const (
+	GameSaved     = game.GameSaved
+	GameLoaded    = game.GameLoaded
+	CommandFailed = game.CommandFailed
+)
+
+// Where a game gets its random numbers from, see WithRNG
+type (
+	RNG         = game.RNG
+	ScriptedRNG = game.ScriptedRNG
+	Roll        = game.Roll
+)
+
+// NewMathRNG returns an RNG from math/rand, the kind a game starts with
+func NewMathRNG(seed int64) RNG {
+	return game.NewMathRNG(seed)
+}
+
+// NewPCGRNG returns an RNG from the PCG generator in math/rand/v2
+func NewPCGRNG(seed1, seed2 uint64) RNG {
+	return game.NewPCGRNG(seed1, seed2)
+}
+
+// NewCryptoRNG returns an RNG drawing from crypto/rand
+func NewCryptoRNG() RNG {
+	return game.NewCryptoRNG()
+}
+
+// NewScriptedRNG returns an RNG that plays back the rolls in order, for
+// testing exact scenarios
+func NewScriptedRNG(rolls ...Roll) *ScriptedRNG {
+	return game.NewScriptedRNG(rolls...)
+}
+
+// Chance is a scripted roll for a chance, such as whether an attack lands.
+// Attacks land on a high roll, while crits, poison and stuns happen on a low
+// one.
+func Chance(f float64) Roll {
+	return game.Chance(f)
+}
+
+// Index is a scripted roll for a pick, such as which bee is hit
+func Index(i int) Roll {
+	return game.Index(i)
+}
+
+// ParseAction turns player input such as "hit" or "hit queen" into an Action
+func ParseAction(input string) (Action, error) {
+	return game.ParseAction(input)
+}